@@ -3,7 +3,6 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -16,7 +15,6 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
-	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
 )
 
 // Service URLs
@@ -40,20 +38,65 @@ func main() {
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 
-	// Routes
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+	// Request/response routes
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(60 * time.Second))
+
+		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
+
+		// WhatsApp webhook - proxy to WhatsApp service
+		r.Get("/webhook", proxyHandler(whatsappServiceURL+"/webhook"))
+		r.Post("/webhook", proxyHandler(whatsappServiceURL+"/webhook"))
+
+		// LLM generation is not proxied: it acts for the user_id in the request, so only the
+		// WhatsApp service may call the LLM service, with LLM_SERVICE_TOKEN
+
+		// Outbound messages and service windows - proxy to WhatsApp service
+		r.Post("/messages", proxyHandler(whatsappServiceURL+"/messages"))
+		r.Get("/windows/{userID}", func(w http.ResponseWriter, r *http.Request) {
+			proxyHandler(whatsappServiceURL+r.URL.Path)(w, r)
+		})
+
+		// Broadcast campaigns - proxy to WhatsApp service
+		r.HandleFunc("/campaigns", proxyHandler(whatsappServiceURL+"/campaigns"))
+		r.HandleFunc("/campaigns/*", func(w http.ResponseWriter, r *http.Request) {
+			proxyHandler(whatsappServiceURL+r.URL.Path)(w, r)
+		})
+
+		// Scheduled messages - proxy to WhatsApp service
+		r.HandleFunc("/schedules", proxyHandler(whatsappServiceURL+"/schedules"))
+		r.HandleFunc("/schedules/*", func(w http.ResponseWriter, r *http.Request) {
+			proxyHandler(whatsappServiceURL+r.URL.Path)(w, r)
+		})
+
+		// Consent records - proxy to WhatsApp service
+		r.HandleFunc("/consents", proxyHandler(whatsappServiceURL+"/consents"))
+		r.HandleFunc("/consents/*", func(w http.ResponseWriter, r *http.Request) {
+			proxyHandler(whatsappServiceURL+r.URL.Path)(w, r)
+		})
+
+		// Human agent API - proxy to WhatsApp service
+		r.HandleFunc("/agent/*", func(w http.ResponseWriter, r *http.Request) {
+			proxyHandler(whatsappServiceURL+r.URL.Path)(w, r)
+		})
 	})
 
-	// WhatsApp webhook - proxy to WhatsApp service
-	r.Get("/webhook", proxyHandler(whatsappServiceURL+"/webhook"))
-	r.Post("/webhook", proxyHandler(whatsappServiceURL+"/webhook"))
+	// Knowledge base administration - proxy to LLM service, with the longer timeout of uploads
+	r.Group(func(r chi.Router) {
+		timeout := time.Duration(cfg.KnowledgeTimeout) * time.Second
+		r.Use(middleware.Timeout(timeout))
 
-	// LLM generation - proxy to LLM service
-	r.Post("/generate", proxyHandler(llmServiceURL+"/generate"))
+		r.HandleFunc("/knowledge/*", func(w http.ResponseWriter, r *http.Request) {
+			proxyHandlerWithTimeout(llmServiceURL+r.URL.Path, timeout)(w, r)
+		})
+	})
+
+	// Streaming routes are long-lived, so they are bounded by the client connection instead of a timeout
+	r.Get("/agent/events", streamProxyHandler(whatsappServiceURL+"/agent/events"))
 
 	// Start server
 	server := &http.Server{
@@ -88,6 +131,12 @@ func main() {
 
 // proxyHandler creates a handler that forwards requests to the specified URL
 func proxyHandler(targetURL string) http.HandlerFunc {
+	return proxyHandlerWithTimeout(targetURL, 60*time.Second)
+}
+
+// proxyHandlerWithTimeout creates a handler that forwards requests to the specified URL,
+// waiting up to timeout for the response
+func proxyHandlerWithTimeout(targetURL string, timeout time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Create a new request
 		proxyReq, err := http.NewRequest(r.Method, targetURL, nil)
@@ -117,7 +166,7 @@ func proxyHandler(targetURL string) http.HandlerFunc {
 		proxyReq.URL.RawQuery = r.URL.RawQuery
 
 		// Send the request
-		client := &http.Client{Timeout: 60 * time.Second}
+		client := &http.Client{Timeout: timeout}
 		resp, err := client.Do(proxyReq)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Failed to send proxy request: %v", err), http.StatusBadGateway)
@@ -140,6 +189,60 @@ func proxyHandler(targetURL string) http.HandlerFunc {
 	}
 }
 
+// streamProxyHandler creates a handler that forwards a streaming response without buffering it
+func streamProxyHandler(targetURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		// Create a new request bound to the client connection
+		proxyReq, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, r.Body)
+		if err != nil {
+			http.Error(w, "Failed to create proxy request", http.StatusInternalServerError)
+			return
+		}
+
+		// Copy headers and query parameters
+		proxyReq.Header = r.Header.Clone()
+		proxyReq.URL.RawQuery = r.URL.RawQuery
+
+		// Send the request
+		resp, err := http.DefaultClient.Do(proxyReq)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to send proxy request: %v", err), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+
+		// Copy the response headers and status code
+		for key, values := range resp.Header {
+			for _, value := range values {
+				w.Header().Add(key, value)
+			}
+		}
+		w.WriteHeader(resp.StatusCode)
+		flusher.Flush()
+
+		// Copy the response body, flushing each chunk as soon as it arrives
+		buf := make([]byte, 4096)
+		for {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+					return
+				}
+				flusher.Flush()
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
 // Helper function to get environment variable with a default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/llm"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// registerFlowTool lets the model start the forms the WhatsApp service offers for a request.
+// The service runs the form after sending the reply.
+func registerFlowTool(client *llm.Client) error {
+	return client.RegisterTool(llm.Tool{
+		Name:        "start_flow",
+		Description: "Start one of the listed forms, which asks the user for the details step by step, e.g. a delivery address or a return request",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the form, from the list of forms",
+				},
+			},
+			"required": []string{"name"},
+		},
+		Handler: startFlow,
+		Available: func(request *models.LLMRequest) bool {
+			return len(request.Flows) > 0
+		},
+	})
+}
+
+// startFlow checks that the form is offered to the user; the WhatsApp service starts it
+func startFlow(ctx context.Context, arguments string) (string, error) {
+	var args struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.Name == "" {
+		return "", errors.New("name is required")
+	}
+
+	request, ok := llm.RequestFromContext(ctx)
+	if !ok {
+		return "", errors.New("forms are only available in a conversation with a user")
+	}
+	for _, flow := range request.Flows {
+		if flow.Name == args.Name {
+			return "The form will start after your reply. Tell the user briefly that you'll ask a few questions.", nil
+		}
+	}
+	return "", fmt.Errorf("unknown form %q", args.Name)
+}
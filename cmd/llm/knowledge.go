@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/document"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/knowledge"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/llm"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// setupKnowledgeBase opens the knowledge base and enables retrieval on the LLM client.
+// It returns nil when no knowledge base is configured.
+func setupKnowledgeBase(cfg *config.Config, client *llm.Client) (*knowledge.Base, error) {
+	if cfg.KnowledgeDir == "" && cfg.KnowledgeIndexPath == "" {
+		return nil, nil
+	}
+
+	embedder, err := llm.NewEmbedder(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kb, err := knowledge.NewBase(cfg, embedder)
+	if err != nil {
+		return nil, err
+	}
+	client.SetRetriever(kb)
+
+	// Index the source directory in the background so startup isn't blocked by embedding
+	if cfg.KnowledgeDir != "" {
+		go func() {
+			if err := kb.IngestDir(context.Background(), cfg.KnowledgeDir); err != nil {
+				log.Printf("Failed to ingest knowledge directory: %v", err)
+				return
+			}
+			log.Printf("Knowledge directory %s indexed", cfg.KnowledgeDir)
+		}()
+	}
+
+	return kb, nil
+}
+
+// maxKnowledgeUploadSize limits the size of uploaded knowledge documents
+const maxKnowledgeUploadSize = 20 << 20
+
+// knowledgeRoutes returns the knowledge base admin API
+func knowledgeRoutes(cfg *config.Config, kb *knowledge.Base) http.Handler {
+	r := chi.NewRouter()
+	r.Use(auth.RequireToken(cfg.AdminAPIToken))
+
+	// Refuse every request when no knowledge base is configured
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if kb == nil {
+				http.Error(w, "Knowledge base is not configured", http.StatusNotFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	// List documents
+	r.Get("/documents", func(w http.ResponseWriter, r *http.Request) {
+		docs, err := kb.Documents(r.Context())
+		if err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, docs)
+	})
+
+	// Upload a document as the "file" field of a multipart form
+	r.Post("/documents", func(w http.ResponseWriter, r *http.Request) {
+		name, data, err := readUpload(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		doc, err := kb.Upload(r.Context(), name, data)
+		if err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, doc)
+	})
+
+	// Get a document
+	r.Get("/documents/*", func(w http.ResponseWriter, r *http.Request) {
+		doc, err := kb.Document(r.Context(), chi.URLParam(r, "*"))
+		if err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+	})
+
+	// Replace the content of a document
+	r.Put("/documents/*", func(w http.ResponseWriter, r *http.Request) {
+		_, data, err := readUpload(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		doc, err := kb.Update(r.Context(), chi.URLParam(r, "*"), data)
+		if err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+	})
+
+	// Delete a document
+	r.Delete("/documents/*", func(w http.ResponseWriter, r *http.Request) {
+		if err := kb.Delete(r.Context(), chi.URLParam(r, "*")); err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Start reindexing; ?force=true re-embeds unchanged documents too
+	r.Post("/reindex", func(w http.ResponseWriter, r *http.Request) {
+		force := r.URL.Query().Get("force") == "true"
+		if err := kb.StartReindex(force); err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, kb.ReindexStatus())
+	})
+
+	// Reindexing status
+	r.Get("/reindex", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, kb.ReindexStatus())
+	})
+
+	// Show which passages a question retrieves and their scores
+	r.Post("/query", func(w http.ResponseWriter, r *http.Request) {
+		var request models.KnowledgeQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Query == "" {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if request.TopK <= 0 {
+			request.TopK = cfg.KnowledgeTopK
+		}
+
+		results, err := kb.Search(r.Context(), request.Query, request.TopK)
+		if err != nil {
+			writeKnowledgeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, models.KnowledgeQueryResponse{
+			Query:    request.Query,
+			MinScore: cfg.KnowledgeMinScore,
+			Results:  results,
+		})
+	})
+
+	return r
+}
+
+// readUpload reads the "file" field of a multipart upload
+func readUpload(w http.ResponseWriter, r *http.Request) (string, []byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxKnowledgeUploadSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", nil, fmt.Errorf("a document must be uploaded in the \"file\" form field: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	return header.Filename, data, nil
+}
+
+// writeKnowledgeError maps knowledge base errors to HTTP responses
+func writeKnowledgeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, knowledge.ErrDocumentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, knowledge.ErrInvalidDocumentID), errors.Is(err, document.ErrUnsupportedFormat):
+		status = http.StatusBadRequest
+	case errors.Is(err, knowledge.ErrReindexRunning):
+		status = http.StatusConflict
+	case errors.Is(err, knowledge.ErrNoSourceDir):
+		status = http.StatusServiceUnavailable
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// writeJSON writes a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
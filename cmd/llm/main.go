@@ -3,15 +3,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed timezone data, which the container image lacks
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/llm"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
@@ -27,16 +30,83 @@ func main() {
 		log.Fatalf("Failed to create LLM client: %v", err)
 	}
 
+	// Register the tools the model may call
+	if err := registerTools(cfg, llmClient); err != nil {
+		log.Fatalf("Failed to register tools: %v", err)
+	}
+
+	// Enable answers from the knowledge base
+	kb, err := setupKnowledgeBase(cfg, llmClient)
+	if err != nil {
+		log.Fatalf("Failed to set up knowledge base: %v", err)
+	}
+
 	// Create router
 	r := chi.NewRouter()
 
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(120 * time.Second))
 
-	// Routes
-	r.Post("/generate", func(w http.ResponseWriter, r *http.Request) {
+	// Request/response routes
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(120 * time.Second))
+
+		// Generation and conversations act for the request's user_id, so only the WhatsApp service may call them
+		requireService := auth.RequireToken(cfg.LLMServiceToken)
+
+		r.With(requireService).Post("/generate", func(w http.ResponseWriter, r *http.Request) {
+			// Decode the request
+			var request models.LLMRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			// Validate the model and generation parameters
+			if err := llmClient.ValidateRequest(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			// Generate a response
+			ctx := r.Context()
+			response, err := llmClient.GenerateResponse(ctx, &request)
+			if err != nil {
+				http.Error(w, "Failed to generate response", http.StatusInternalServerError)
+				return
+			}
+
+			// Return the response
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+		})
+
+		// Forget a user's conversation
+		r.With(requireService).Delete("/conversations/{userID}", func(w http.ResponseWriter, r *http.Request) {
+			if err := llmClient.ResetConversation(r.Context(), chi.URLParam(r, "userID")); err != nil {
+				http.Error(w, "Failed to reset conversation", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		// Health check endpoint
+		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
+	})
+
+	// Knowledge base administration; uploads are parsed and embedded before the response, so
+	// they get a longer timeout
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(time.Duration(cfg.KnowledgeTimeout) * time.Second))
+		r.Mount("/knowledge", knowledgeRoutes(cfg, kb))
+	})
+
+	// Streaming generation using Server-Sent Events, bounded by the client connection instead of a timeout
+	r.With(auth.RequireToken(cfg.LLMServiceToken)).Post("/generate/stream", func(w http.ResponseWriter, r *http.Request) {
 		// Decode the request
 		var request models.LLMRequest
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -44,23 +114,42 @@ func main() {
 			return
 		}
 
-		// Generate a response
-		ctx := r.Context()
-		response, err := llmClient.GenerateResponse(ctx, &request)
-		if err != nil {
-			http.Error(w, "Failed to generate response", http.StatusInternalServerError)
+		// Validate the model and generation parameters
+		if err := llmClient.ValidateRequest(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		// Return the response
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
-	})
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
 
-	// Health check endpoint
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		// Start the event stream
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		flusher.Flush()
+
+		// Forward each chunk as it is generated
+		ctx := r.Context()
+		response, err := llmClient.StreamResponse(ctx, &request, func(ctx context.Context, chunk []byte) error {
+			return writeEvent(w, flusher, "chunk", models.LLMStreamChunk{Text: string(chunk)})
+		})
+		if err != nil {
+			writeEvent(w, flusher, "error", models.LLMResponse{Error: "failed to generate response"})
+			return
+		}
+		if response.Error != "" {
+			writeEvent(w, flusher, "error", response)
+			return
+		}
+
+		// Send the complete response last
+		writeEvent(w, flusher, "done", response)
 	})
 
 	// Start server
@@ -92,4 +181,26 @@ func main() {
 	}
 
 	log.Println("Server stopped")
+}
+
+// writeEvent writes a Server-Sent Event with a JSON payload and flushes it to the client
+func writeEvent(w http.ResponseWriter, flusher http.Flusher, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// Helper function to get environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
 } 
\ No newline at end of file
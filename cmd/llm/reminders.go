@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/llm"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// reminderClient calls the WhatsApp service's scheduled message API on behalf of the user
+type reminderClient struct {
+	serviceURL string
+	token      string
+	client     *http.Client
+}
+
+// registerReminderTools lets the model schedule, list and cancel reminders for the user
+func registerReminderTools(client *llm.Client, serviceURL, token string) error {
+	rc := &reminderClient{
+		serviceURL: strings.TrimSuffix(serviceURL, "/"),
+		token:      token,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+
+	tools := []llm.Tool{
+		{
+			Name:        "schedule_reminder",
+			Description: "Send the user a WhatsApp message at a later time, e.g. when they ask to be reminded of something",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"text": map[string]interface{}{
+						"type":        "string",
+						"description": "The message sent to the user, written to them, e.g. \"Reminder: pay the electricity bill\"",
+					},
+					"send_at": map[string]interface{}{
+						"type":        "string",
+						"description": "When to send it, as a local time in the user's timezone formatted 2006-01-02T15:04",
+					},
+				},
+				"required": []string{"text", "send_at"},
+			},
+			Handler: rc.schedule,
+		},
+		{
+			Name:        "list_reminders",
+			Description: "List the user's pending reminders with their IDs",
+			Handler:     rc.list,
+		},
+		{
+			Name:        "cancel_reminder",
+			Description: "Cancel one of the user's pending reminders",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the reminder, from list_reminders",
+					},
+				},
+				"required": []string{"id"},
+			},
+			Handler: rc.cancel,
+		},
+	}
+	for _, tool := range tools {
+		if err := client.RegisterTool(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schedule schedules a reminder for the user
+func (rc *reminderClient) schedule(ctx context.Context, arguments string) (string, error) {
+	request, err := requestUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	var args struct {
+		Text   string `json:"text"`
+		SendAt string `json:"send_at"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	body, err := rc.call(ctx, http.MethodPost, "/schedules", models.ScheduleRequest{
+		UserID:   request.UserID,
+		Text:     args.Text,
+		SendAt:   args.SendAt,
+		Timezone: request.Timezone,
+		Source:   models.ScheduleSourceAssistant,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var msg models.ScheduledMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return "", fmt.Errorf("failed to decode scheduled message: %w", err)
+	}
+	return fmt.Sprintf("Reminder %s scheduled for %s (%s)", msg.ID, msg.SendAt.Format("Monday, 2 January 2006 15:04"), msg.Timezone), nil
+}
+
+// list lists the user's pending reminders
+func (rc *reminderClient) list(ctx context.Context, arguments string) (string, error) {
+	request, err := requestUser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{"user_id": {request.UserID}, "status": {models.SchedulePending}}
+	body, err := rc.call(ctx, http.MethodGet, "/schedules?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var messages []models.ScheduledMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		return "", fmt.Errorf("failed to decode scheduled messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return "The user has no pending reminders", nil
+	}
+	var b strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "%s: %s (%s) - %s\n", msg.ID, msg.SendAt.Format("Monday, 2 January 2006 15:04"), msg.Timezone, msg.Text)
+	}
+	return b.String(), nil
+}
+
+// cancel cancels one of the user's pending reminders
+func (rc *reminderClient) cancel(ctx context.Context, arguments string) (string, error) {
+	request, err := requestUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.ID == "" {
+		return "", errors.New("id is required")
+	}
+
+	query := url.Values{"user_id": {request.UserID}}
+	if _, err := rc.call(ctx, http.MethodDelete, "/schedules/"+url.PathEscape(args.ID)+"?"+query.Encode(), nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Reminder %s cancelled", args.ID), nil
+}
+
+// call sends a request to the scheduled message API and returns the response body
+func (rc *reminderClient) call(ctx context.Context, method, path string, payload interface{}) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rc.serviceURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+rc.token)
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call WhatsApp service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		// The scheduler's error messages explain what to fix, e.g. a time in the past
+		return nil, errors.New(strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// requestUser returns the request a tool is called for, which must identify the user
+func requestUser(ctx context.Context) (*models.LLMRequest, error) {
+	request, ok := llm.RequestFromContext(ctx)
+	if !ok || request.UserID == "" {
+		return nil, errors.New("reminders are only available in a conversation with a user")
+	}
+	return request, nil
+}
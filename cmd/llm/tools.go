@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/llm"
+)
+
+// registerTools registers the built-in tools and the configured webhook tools
+func registerTools(cfg *config.Config, client *llm.Client) error {
+	if err := client.RegisterTool(llm.Tool{
+		Name:        "current_time",
+		Description: "Get the current date and time, optionally in a specific IANA timezone such as Asia/Jakarta",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"timezone": map[string]interface{}{
+					"type":        "string",
+					"description": "IANA timezone name; defaults to UTC",
+				},
+			},
+		},
+		Handler: currentTime,
+	}); err != nil {
+		return err
+	}
+
+	// Let the model start the forms offered by the WhatsApp service
+	if err := registerFlowTool(client); err != nil {
+		return err
+	}
+
+	// Let the model schedule reminders through the WhatsApp service. They act for the request's
+	// user_id, which is only trusted when callers must present the service token.
+	if cfg.OutboundAPIToken != "" && cfg.LLMServiceToken != "" {
+		serviceURL := getEnv("WHATSAPP_SERVICE_URL", "http://whatsapp-service:8081")
+		if err := registerReminderTools(client, serviceURL, cfg.OutboundAPIToken); err != nil {
+			return err
+		}
+	}
+
+	// Load the webhook tools declared by the operators
+	if cfg.WebhookToolsFile == "" {
+		return nil
+	}
+	tools, err := llm.LoadWebhookTools(cfg.WebhookToolsFile, time.Duration(cfg.WebhookToolTimeout)*time.Second)
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		if err := client.RegisterTool(tool); err != nil {
+			return err
+		}
+		log.Printf("Registered webhook tool %s", tool.Name)
+	}
+	return nil
+}
+
+// currentTime returns the current time in the requested timezone
+func currentTime(ctx context.Context, arguments string) (string, error) {
+	var args struct {
+		Timezone string `json:"timezone"`
+	}
+	if arguments != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	loc := time.UTC
+	if args.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(args.Timezone); err != nil {
+			return "", fmt.Errorf("unknown timezone %q", args.Timezone)
+		}
+	}
+	return time.Now().In(loc).Format("Monday, 2 January 2006 15:04 MST"), nil
+}
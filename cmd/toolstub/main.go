@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// The tool stub serves canned responses for the endpoints in tools.example.json,
+// so webhook tools can be exercised locally without the real internal services.
+func main() {
+	port := getEnv("PORT", "8090")
+
+	// Start server
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: stubRoutes(),
+	}
+
+	// Start server in a goroutine
+	go func() {
+		log.Printf("Tool stub server starting on port %s", port)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// Shutdown server
+	log.Println("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Fatalf("Server shutdown failed: %v", err)
+	}
+
+	log.Println("Server stopped")
+}
+
+// stubRoutes creates the router serving the stub endpoints
+func stubRoutes() http.Handler {
+	// Create router
+	r := chi.NewRouter()
+
+	// Middleware
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+
+	// Order status lookup
+	r.Get("/orders/{orderID}", func(w http.ResponseWriter, r *http.Request) {
+		orderID := chi.URLParam(r, "orderID")
+		if orderID == "404" {
+			http.Error(w, `{"error":"order not found"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"order_id":  orderID,
+				"status":    "shipped",
+				"carrier":   "JNE",
+				"eta":       time.Now().AddDate(0, 0, 2).Format("2006-01-02"),
+				"total_idr": 249000,
+			},
+		})
+	})
+
+	// Loyalty points lookup
+	r.Get("/loyalty/{phone}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"phone":  chi.URLParam(r, "phone"),
+			"points": 1250,
+			"tier":   "gold",
+		})
+	})
+
+	// Slow endpoint for exercising tool timeouts
+	r.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(30 * time.Second):
+			writeJSON(w, map[string]interface{}{"status": "done"})
+		case <-r.Context().Done():
+		}
+	})
+
+	return r
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Helper function to get environment variable with a default value
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
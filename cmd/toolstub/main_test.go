@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/llm"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// exampleURL is the stub address used in tools.example.json
+const exampleURL = "http://localhost:8090"
+
+// TestExampleTools runs the tools declared in tools.example.json against the stub
+func TestExampleTools(t *testing.T) {
+	// Record the paths the tools call
+	var mu sync.Mutex
+	var paths []string
+	routes := stubRoutes()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		routes.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	tools := loadExampleTools(t, server.URL)
+
+	tests := []struct {
+		name      string
+		tool      string
+		userID    string
+		arguments string
+		want      string
+		wantPath  string
+		wantErr   string
+	}{
+		{
+			name:      "order status",
+			tool:      "order_status",
+			userID:    "6281234567890",
+			arguments: `{"order_id":"INV-10023"}`,
+			want:      `"data.status":"shipped"`,
+			wantPath:  "/orders/INV-10023",
+		},
+		{
+			name:      "order not found",
+			tool:      "order_status",
+			userID:    "6281234567890",
+			arguments: `{"order_id":"404"}`,
+			wantErr:   "status code 404",
+		},
+		{
+			name:      "missing argument",
+			tool:      "order_status",
+			userID:    "6281234567890",
+			arguments: `{}`,
+			wantErr:   "no value for placeholder {order_id}",
+		},
+		{
+			name:      "loyalty points of the requesting user",
+			tool:      "loyalty_points",
+			userID:    "6281234567890",
+			arguments: `{}`,
+			want:      `"tier":"gold"`,
+			wantPath:  "/loyalty/6281234567890",
+		},
+		{
+			name:      "model can't choose another customer",
+			tool:      "loyalty_points",
+			userID:    "6281234567890",
+			arguments: `{"user_id":"6289999999999","phone":"6289999999999"}`,
+			want:      `"points":1250`,
+			wantPath:  "/loyalty/6281234567890",
+		},
+		{
+			name:      "no requesting user",
+			tool:      "loyalty_points",
+			arguments: `{}`,
+			wantErr:   "no value for placeholder {{user_id}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, ok := tools[tt.tool]
+			if !ok {
+				t.Fatalf("tool %q not declared", tt.tool)
+			}
+			mu.Lock()
+			paths = nil
+			mu.Unlock()
+
+			ctx := llm.ContextWithRequest(context.Background(), &models.LLMRequest{UserID: tt.userID})
+			result, err := tool.Handler(ctx, tt.arguments)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Handler() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Handler() error = %v", err)
+			}
+			if !strings.Contains(result, tt.want) {
+				t.Errorf("Handler() = %s, want it to contain %s", result, tt.want)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(paths) != 1 || paths[0] != tt.wantPath {
+				t.Errorf("called %v, want %s", paths, tt.wantPath)
+			}
+		})
+	}
+}
+
+// loadExampleTools creates the tools in tools.example.json, pointed at the given base URL
+func loadExampleTools(t *testing.T, baseURL string) map[string]llm.Tool {
+	t.Helper()
+
+	data, err := os.ReadFile("../../tools.example.json")
+	if err != nil {
+		t.Fatalf("failed to read tools.example.json: %v", err)
+	}
+	var file struct {
+		Tools []llm.WebhookToolDefinition `json:"tools"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("failed to parse tools.example.json: %v", err)
+	}
+
+	tools := make(map[string]llm.Tool)
+	for _, def := range file.Tools {
+		def.URL = strings.Replace(def.URL, exampleURL, baseURL, 1)
+		tool, err := llm.NewWebhookTool(def, 5*time.Second)
+		if err != nil {
+			t.Fatalf("NewWebhookTool(%s) error = %v", def.Name, err)
+		}
+		tools[def.Name] = tool
+	}
+	return tools
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/consent"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// agentRoutes returns the API human agents use to handle handed-off conversations
+func agentRoutes(cfg *config.Config, client *whatsapp.Client) http.Handler {
+	r := chi.NewRouter()
+	r.Use(auth.RequireToken(cfg.AgentAPIToken))
+
+	// Real-time conversation events; long-lived, so not bounded by a timeout
+	r.Get("/events", eventsHandler)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(60 * time.Second))
+		agentHandoffRoutes(r, client)
+	})
+
+	return r
+}
+
+// agentHandoffRoutes adds the routes for reading and handling handed-off conversations
+func agentHandoffRoutes(r chi.Router, client *whatsapp.Client) {
+	// List conversations waiting for or handled by an agent, including those queued until opening, oldest first
+	r.Get("/handoffs", func(w http.ResponseWriter, r *http.Request) {
+		all, err := sessions.List(r.Context())
+		if err != nil {
+			http.Error(w, "Failed to list sessions", http.StatusInternalServerError)
+			return
+		}
+
+		handoffs := []models.Handoff{}
+		for _, sess := range all {
+			if sess.Mode != models.SessionModeHuman && sess.Mode != models.SessionModeQueued {
+				continue
+			}
+			handoff := models.Handoff{
+				UserID:        sess.UserID,
+				Mode:          sess.Mode,
+				Reason:        sess.HandoffReason,
+				Since:         sess.HandoffAt,
+				LastMessageAt: sess.LastMessageAt,
+			}
+			if len(sess.Thread) > 0 {
+				handoff.LastMessage = sess.Thread[len(sess.Thread)-1].Text
+			}
+			handoffs = append(handoffs, handoff)
+		}
+		sort.Slice(handoffs, func(i, j int) bool {
+			return handoffs[i].Since.Before(handoffs[j].Since)
+		})
+		writeJSON(w, http.StatusOK, handoffs)
+	})
+
+	// Read a conversation's session and thread
+	r.Get("/handoffs/{userID}", func(w http.ResponseWriter, r *http.Request) {
+		userID := phoneParam(r, "userID")
+		if userID == "" {
+			http.Error(w, "Invalid phone number", http.StatusBadRequest)
+			return
+		}
+
+		sess, err := sessions.Get(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, sess)
+	})
+
+	// Reply to the user; this takes the conversation over from the bot if needed
+	r.Post("/handoffs/{userID}/messages", func(w http.ResponseWriter, r *http.Request) {
+		var request models.AgentReplyRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(request.Text) == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+
+		userID := phoneParam(r, "userID")
+		if userID == "" {
+			http.Error(w, "Invalid phone number", http.StatusBadRequest)
+			return
+		}
+		unlock := lockUser(userID)
+		defer unlock()
+
+		sess, err := sessions.Get(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+
+		// Agents can only write freely within the service window; text can fall back to a template
+		reqBody := whatsapp.TextMessage(userID, request.Text)
+		if window := windowOf(sess, time.Now()); !window.Open {
+			if reqBody = fallbackMessage(userID, request.Text); reqBody == nil {
+				http.Error(w, windowError(window).Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		_, err = client.Send(reqBody)
+		if errors.Is(err, consent.ErrOptedOut) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to send agent reply to %s: %v", userID, err)
+			http.Error(w, "Failed to send message", http.StatusBadGateway)
+			return
+		}
+		startHandoff(sess, handoffAgent)
+		recordMessage(sess, models.SenderAgent, request.AgentID, request.Text)
+
+		if err := saveSession(r.Context(), sess); err != nil {
+			http.Error(w, "Failed to save session", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, sess)
+	})
+
+	// Hand the conversation back to the bot
+	r.Post("/handoffs/{userID}/release", endHandoffHandler(client, models.SessionModeBot, "handoff.ended"))
+
+	// Close the conversation; the user's next message starts over with the bot
+	r.Post("/handoffs/{userID}/close", endHandoffHandler(client, models.SessionModeClosed, "handoff.closed"))
+}
+
+// endHandoffHandler ends a handoff in the given mode and sends the user a notice
+func endHandoffHandler(client *whatsapp.Client, mode, notice string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := phoneParam(r, "userID")
+		if userID == "" {
+			http.Error(w, "Invalid phone number", http.StatusBadRequest)
+			return
+		}
+		unlock := lockUser(userID)
+		defer unlock()
+
+		sess, err := sessions.Get(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Failed to load session", http.StatusInternalServerError)
+			return
+		}
+		if sess.Mode != models.SessionModeHuman && sess.Mode != models.SessionModeQueued {
+			http.Error(w, "Conversation is not handed off", http.StatusConflict)
+			return
+		}
+
+		endHandoff(sess, mode)
+		notify(client, sess, notice)
+
+		if err := saveSession(r.Context(), sess); err != nil {
+			http.Error(w, "Failed to save session", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, sess)
+	}
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/campaign"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// maxRecipientListSize limits the size of uploaded recipient lists
+const maxRecipientListSize = 20 << 20
+
+// campaigns sends broadcast campaigns and tracks their delivery
+var campaigns *campaign.Manager
+
+// setupCampaigns loads the stored campaigns and starts sending scheduled ones
+func setupCampaigns(cfg *config.Config, client *whatsapp.Client) error {
+	store, err := campaign.NewStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	campaigns, err = campaign.NewManager(ctx, cfg, store, client)
+	if err != nil {
+		return err
+	}
+	go campaigns.Run(ctx)
+	return nil
+}
+
+// campaignRoutes returns the campaign admin API
+func campaignRoutes(cfg *config.Config) http.Handler {
+	r := chi.NewRouter()
+	r.Use(auth.RequireToken(cfg.AdminAPIToken))
+
+	// List campaigns
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, campaigns.List())
+	})
+
+	// Create a campaign
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var request models.CampaignRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRecipientListSize)).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		c, err := campaigns.Create(r.Context(), &request)
+		if err != nil {
+			writeCampaignError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, c)
+	})
+
+	// Get a campaign with its recipients, optionally only those in one status
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		c, err := campaigns.Get(chi.URLParam(r, "id"))
+		if err != nil {
+			writeCampaignError(w, err)
+			return
+		}
+		if status := r.URL.Query().Get("status"); status != "" {
+			var recipients []models.Recipient
+			for _, recipient := range c.Recipients {
+				if recipient.Status == status {
+					recipients = append(recipients, recipient)
+				}
+			}
+			c.Recipients = recipients
+		}
+		writeJSON(w, http.StatusOK, c)
+	})
+
+	// Add recipients as CSV (phone, then template parameters) or a JSON array
+	r.Post("/{id}/recipients", func(w http.ResponseWriter, r *http.Request) {
+		body := http.MaxBytesReader(w, r.Body, maxRecipientListSize)
+
+		var recipients []models.Recipient
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "text/csv" {
+			var err error
+			recipients, err = campaign.ParseRecipientsCSV(body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else if err := json.NewDecoder(body).Decode(&recipients); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		c, err := campaigns.AddRecipients(r.Context(), chi.URLParam(r, "id"), recipients)
+		if err != nil {
+			writeCampaignError(w, err)
+			return
+		}
+		c.Recipients = nil
+		writeJSON(w, http.StatusOK, c)
+	})
+
+	// Schedule a campaign, or start it now without a start time
+	r.Post("/{id}/schedule", func(w http.ResponseWriter, r *http.Request) {
+		var request models.CampaignScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		var startAt time.Time
+		if request.StartAt != nil {
+			startAt = *request.StartAt
+		}
+		c, err := campaigns.Schedule(r.Context(), chi.URLParam(r, "id"), startAt)
+		writeCampaign(w, c, err)
+	})
+
+	r.Post("/{id}/pause", func(w http.ResponseWriter, r *http.Request) {
+		c, err := campaigns.Pause(r.Context(), chi.URLParam(r, "id"))
+		writeCampaign(w, c, err)
+	})
+
+	r.Post("/{id}/resume", func(w http.ResponseWriter, r *http.Request) {
+		c, err := campaigns.Resume(r.Context(), chi.URLParam(r, "id"))
+		writeCampaign(w, c, err)
+	})
+
+	r.Post("/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		c, err := campaigns.Cancel(r.Context(), chi.URLParam(r, "id"))
+		writeCampaign(w, c, err)
+	})
+
+	return r
+}
+
+// writeCampaign writes a campaign summary, or the error that prevented the change
+func writeCampaign(w http.ResponseWriter, c *models.Campaign, err error) {
+	if err != nil {
+		writeCampaignError(w, err)
+		return
+	}
+	c.Recipients = nil
+	writeJSON(w, http.StatusOK, c)
+}
+
+// writeCampaignError maps campaign errors to HTTP status codes
+func writeCampaignError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, campaign.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, campaign.ErrInvalidState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, campaign.ErrInvalid), errors.Is(err, campaign.ErrNoRecipients):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Campaign request failed: %v", err)
+		http.Error(w, "Campaign request failed", http.StatusInternalServerError)
+	}
+}
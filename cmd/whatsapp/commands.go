@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/command"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// commands handles slash commands and their localized keywords
+var commands *command.Dispatcher
+
+// setupCommands creates the command dispatcher with the built-in commands.
+// Custom commands are added the same way, with commands.Register.
+func setupCommands(cfg *config.Config) error {
+	commands = command.NewDispatcher(cfg.DefaultLanguage)
+
+	// Bare words like "reset" or "agent" are common in questions the intent rules and the
+	// assistant should answer, so only help works without the slash unless configured
+	var resetKeywords, humanKeywords []string
+	if cfg.CommandKeywords {
+		resetKeywords = []string{"reset", "mulai ulang"}
+		humanKeywords = []string{"human", "agent", "talk to a human", "customer service", "bicara dengan petugas", "hubungi cs"}
+	}
+
+	builtins := []command.Command{
+		{
+			Name:        "help",
+			Aliases:     []string{"bantuan", "menu"},
+			Keywords:    []string{"help", "bantuan", "menu"},
+			Description: "command.help",
+			Handler:     helpCommand,
+		},
+		{
+			Name:        "reset",
+			Aliases:     []string{"ulang"},
+			Keywords:    resetKeywords,
+			Description: "command.reset",
+			Handler:     resetCommand,
+		},
+		{
+			Name:        "lang",
+			Aliases:     []string{"language", "bahasa"},
+			Description: "command.lang",
+			Handler:     langCommand,
+		},
+		{
+			Name:        "timezone",
+			Aliases:     []string{"tz", "zonawaktu"},
+			Description: "command.timezone",
+			Handler:     timezoneCommand,
+		},
+		{
+			Name:        "voice",
+			Aliases:     []string{"suara"},
+			Description: "command.voice",
+			Handler:     voiceCommand,
+		},
+		{
+			Name:        "human",
+			Aliases:     []string{"agent", "agen", "cs"},
+			Keywords:    humanKeywords,
+			Description: "command.human",
+			Handler:     humanCommand,
+		},
+	}
+	for _, cmd := range builtins {
+		if err := commands.Register(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleCommand runs the command in a message, if any, and sends its reply.
+// It returns false when the message is not a command. The caller saves the session.
+func handleCommand(ctx context.Context, client *whatsapp.Client, message models.Message, sess *models.Session) bool {
+	reply, handled, err := commands.Dispatch(ctx, message, sess)
+	if !handled {
+		return false
+	}
+	if err != nil {
+		log.Printf("Command from %s failed: %v", message.From, err)
+	}
+
+	if reply != "" {
+		if err := client.SendMessage(message.From, reply); err != nil {
+			log.Printf("Failed to send command reply: %v", err)
+			return true
+		}
+		recordMessage(sess, models.SenderBot, "", reply)
+	}
+	return true
+}
+
+// helpCommand lists the available commands
+func helpCommand(ctx context.Context, req *command.Request) (string, error) {
+	var b strings.Builder
+	b.WriteString(i18n.T(req.Language, "help.header"))
+	for _, cmd := range commands.Commands() {
+		fmt.Fprintf(&b, "\n%s%s - %s", command.Prefix, cmd.Name, i18n.T(req.Language, cmd.Description))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(i18n.T(req.Language, "help.footer"))
+	return b.String(), nil
+}
+
+// resetCommand makes the LLM service forget the user's conversation, drops the documents they
+// shared and leaves any form or handoff, so the user really starts over with the bot
+func resetCommand(ctx context.Context, req *command.Request) (string, error) {
+	if err := resetConversation(ctx, req.Message.From); err != nil {
+		return "", err
+	}
+	req.Session.Documents = nil
+	if req.Session.Flow != nil {
+		log.Printf("Cancelled flow %s for %s", req.Session.Flow.Name, req.Session.UserID)
+		req.Session.Flow = nil
+	}
+	if req.Session.Mode == models.SessionModeHuman || req.Session.Mode == models.SessionModeQueued {
+		endHandoff(req.Session, models.SessionModeBot)
+	}
+	req.Session.Failures = 0
+	return i18n.T(req.Language, "reset.done"), nil
+}
+
+// langCommand shows or changes the user's reply language; "auto" follows the language they write in
+func langCommand(ctx context.Context, req *command.Request) (string, error) {
+	available := strings.Join(i18n.Codes(), ", ")
+	if len(req.Args) == 0 {
+		return i18n.T(req.Language, "lang.current", i18n.Name(req.Language), available), nil
+	}
+
+	if strings.EqualFold(req.Args[0], "auto") {
+		req.Session.Language = ""
+		return i18n.T(languageOf(req.Session), "lang.auto"), nil
+	}
+
+	lang, ok := i18n.Parse(strings.Join(req.Args, " "))
+	if !ok {
+		return i18n.T(req.Language, "lang.unsupported", strings.Join(req.Args, " "), available), nil
+	}
+	req.Session.Language = lang
+	return i18n.T(lang, "lang.changed"), nil
+}
+
+// timezoneCommand shows or changes the user's timezone, used for reminders and times they mention
+func timezoneCommand(ctx context.Context, req *command.Request) (string, error) {
+	if len(req.Args) == 0 {
+		return i18n.T(req.Language, "timezone.current", timezoneOf(req.Session).String()), nil
+	}
+
+	loc, err := loadTimezone(req.Args[0])
+	if err != nil {
+		return i18n.T(req.Language, "timezone.unknown", req.Args[0]), nil
+	}
+	req.Session.Timezone = loc.String()
+	return i18n.T(req.Language, "timezone.changed", loc.String(), time.Now().In(loc).Format("15:04")), nil
+}
+
+// humanCommand hands the conversation to an agent
+func humanCommand(ctx context.Context, req *command.Request) (string, error) {
+	startHandoff(req.Session, handoffUserRequest)
+	if req.Session.Mode == models.SessionModeQueued {
+		return i18n.T(req.Language, "human.queued", openingTime(req.Session, time.Now())), nil
+	}
+	return i18n.T(req.Language, "human.requested"), nil
+}
+
+// resetConversation deletes the conversation remembered by the LLM service
+func resetConversation(ctx context.Context, userID string) error {
+	req, err := newLLMRequest(ctx, http.MethodDelete, "/conversations/"+url.PathEscape(userID), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call LLM service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("LLM service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
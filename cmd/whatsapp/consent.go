@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/consent"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// consents records who agreed to receive messages
+var consents *consent.Manager
+
+// setupConsent loads the consent store and stops the client from messaging opted-out numbers
+func setupConsent(cfg *config.Config, client *whatsapp.Client) error {
+	store, err := consent.NewStore(cfg)
+	if err != nil {
+		return err
+	}
+	consents = consent.NewManager(cfg, store)
+	client.SetGuard(consents)
+	return nil
+}
+
+// handleConsent opts the user out or back in when the message is a stop or start keyword.
+// It returns false when the message is not a keyword. The caller saves the session.
+func handleConsent(ctx context.Context, client *whatsapp.Client, message models.Message, sess *models.Session) bool {
+	switch consents.Keyword(message.Text) {
+	case consent.KeywordStop:
+		optedOut, err := consents.OptedOut(ctx, message.From)
+		if err != nil {
+			log.Printf("Failed to check consent for %s: %v", message.From, err)
+		}
+
+		// Confirm before opting out, since nothing can be sent afterwards
+		if !optedOut {
+			notify(client, sess, "consent.stopped", consents.StartKeyword())
+		}
+		if _, err := consents.Set(ctx, message.From, models.ConsentOptedOut, consent.SourceKeyword); err != nil {
+			log.Printf("Failed to opt out %s: %v", message.From, err)
+		}
+		return true
+
+	case consent.KeywordStart:
+		if _, err := consents.Set(ctx, message.From, models.ConsentOptedIn, consent.SourceKeyword); err != nil {
+			log.Printf("Failed to opt in %s: %v", message.From, err)
+			return true
+		}
+		notify(client, sess, "consent.started", consents.StopKeyword())
+		return true
+
+	default:
+		return false
+	}
+}
+
+// consentRoutes returns the consent admin API
+func consentRoutes(cfg *config.Config) http.Handler {
+	r := chi.NewRouter()
+	r.Use(auth.RequireToken(cfg.AdminAPIToken))
+
+	// List consent records, optionally filtered by status
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		list, err := consents.List(r.Context())
+		if err != nil {
+			log.Printf("Failed to list consents: %v", err)
+			http.Error(w, "Failed to list consents", http.StatusInternalServerError)
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		filtered := make([]*models.Consent, 0, len(list))
+		for _, c := range list {
+			if status == "" || c.Status == status {
+				filtered = append(filtered, c)
+			}
+		}
+		sort.Slice(filtered, func(i, j int) bool { return filtered[i].Phone < filtered[j].Phone })
+		writeJSON(w, http.StatusOK, filtered)
+	})
+
+	// Get the consent record of a phone number
+	r.Get("/{phone}", func(w http.ResponseWriter, r *http.Request) {
+		c, err := consents.Get(r.Context(), chi.URLParam(r, "phone"))
+		if err != nil {
+			log.Printf("Failed to load consent: %v", err)
+			http.Error(w, "Failed to load consent", http.StatusInternalServerError)
+			return
+		}
+		if c == nil {
+			http.Error(w, "No consent recorded for this number", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	})
+
+	// Record a phone number opting in or out, e.g. from a signup form
+	r.Put("/{phone}", func(w http.ResponseWriter, r *http.Request) {
+		var request models.ConsentRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if request.Source == "" {
+			request.Source = consent.SourceAPI
+		}
+
+		c, err := consents.Set(r.Context(), chi.URLParam(r, "phone"), request.Status, request.Source)
+		if errors.Is(err, consent.ErrInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to save consent: %v", err)
+			http.Error(w, "Failed to save consent", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	})
+
+	return r
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"path/filepath"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/document"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// documentTimeout bounds downloading and reading a document
+const documentTimeout = time.Minute
+
+// documentChunkOverlap is the context repeated between the chunks of a document
+const documentChunkOverlap = 150
+
+// docxMIMEType is the MIME type of Word documents
+const docxMIMEType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Document errors
+var (
+	errDocumentTooLong    = errors.New("document has too much text")
+	errDocumentUnreadable = errors.New("document text could not be read")
+)
+
+// Shared document settings
+var (
+	// documentMaxChars limits the extracted text of a document; 0 means no limit
+	documentMaxChars   int
+	documentChunkSize  int
+	documentMaxPerUser int
+	// documentTTL is how long a document is kept after it was shared
+	documentTTL time.Duration
+)
+
+// setupDocuments applies the shared document settings
+func setupDocuments(cfg *config.Config) {
+	documentMaxChars = cfg.DocumentMaxChars
+	documentChunkSize = cfg.DocumentChunkSize
+	documentMaxPerUser = cfg.DocumentMaxPerUser
+	documentTTL = time.Duration(cfg.DocumentTTL) * time.Hour
+}
+
+// shareDocument reads a document the user sent and keeps its text in the session, so the
+// assistant can answer questions about it. It returns nil for other messages.
+func shareDocument(ctx context.Context, client *whatsapp.Client, sess *models.Session, message models.Message) (*models.SharedDocument, error) {
+	if message.Media == nil || message.Media.Type != models.MediaDocument {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, documentTimeout)
+	defer cancel()
+
+	media, err := client.DownloadMedia(ctx, message.Media.ID)
+	if err != nil {
+		return nil, err
+	}
+	name := documentName(message.Media.Filename, media.MIMEType)
+	text, err := document.ExtractText(name, media.Data)
+	if errors.Is(err, document.ErrUnsupportedFormat) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errDocumentUnreadable, err)
+	}
+	if documentMaxChars > 0 && len(text) > documentMaxChars {
+		return nil, errDocumentTooLong
+	}
+
+	doc := models.SharedDocument{
+		Name:     name,
+		Chunks:   document.Chunk(text, documentChunkSize, documentChunkOverlap),
+		SharedAt: time.Now(),
+	}
+
+	// A file sent again replaces the earlier copy; beyond the limit the oldest is dropped
+	documents := []models.SharedDocument{doc}
+	for _, shared := range activeDocuments(sess, doc.SharedAt) {
+		if shared.Name != doc.Name {
+			documents = append(documents, shared)
+		}
+	}
+	if documentMaxPerUser > 0 && len(documents) > documentMaxPerUser {
+		documents = documents[:documentMaxPerUser]
+	}
+	sess.Documents = documents
+
+	log.Printf("Read document %s from %s: %d characters in %d chunks", name, message.From, len(text), len(doc.Chunks))
+	return &doc, nil
+}
+
+// activeDocuments drops the session's expired documents and returns the rest, newest first
+func activeDocuments(sess *models.Session, now time.Time) []models.SharedDocument {
+	if documentTTL <= 0 {
+		return sess.Documents
+	}
+	var active []models.SharedDocument
+	for _, doc := range sess.Documents {
+		if now.Sub(doc.SharedAt) < documentTTL {
+			active = append(active, doc)
+		}
+	}
+	sess.Documents = active
+	return active
+}
+
+// documentFailed tells the user why their document couldn't be read
+func documentFailed(client *whatsapp.Client, sess *models.Session, message models.Message, err error) {
+	name := documentName(message.Media.Filename, message.Media.MIMEType)
+	log.Printf("Failed to read document %s from %s: %v", name, sess.UserID, err)
+
+	switch {
+	case errors.Is(err, document.ErrUnsupportedFormat):
+		notify(client, sess, "document.unsupported", name)
+	case errors.Is(err, whatsapp.ErrMediaTooLarge):
+		notify(client, sess, "document.too_large", name)
+	case errors.Is(err, errDocumentTooLong):
+		notify(client, sess, "document.too_long", name)
+	case errors.Is(err, errDocumentUnreadable):
+		notify(client, sess, "document.unreadable", name)
+	default:
+		notify(client, sess, "document.failed", name)
+	}
+}
+
+// documentName returns the file name of a document, with an extension from its MIME type
+// when the name has none, since the extension decides how its text is extracted
+func documentName(filename, mimeType string) string {
+	name := filepath.Base(filename)
+	if filename == "" {
+		name = "document"
+	}
+	if filepath.Ext(name) != "" {
+		return name
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	switch mediaType {
+	case "application/pdf":
+		return name + ".pdf"
+	case docxMIMEType:
+		return name + ".docx"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return name + exts[0]
+	}
+	return name
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/events"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// eventHeartbeatInterval keeps idle event streams open through proxies
+const eventHeartbeatInterval = 25 * time.Second
+
+// inbox publishes conversation events to connected agents
+var inbox *events.Broker
+
+// publish sends an event to connected agents
+func publish(eventType, userID string, data interface{}) {
+	if inbox != nil {
+		inbox.Publish(eventType, userID, data)
+	}
+}
+
+// eventsHandler streams conversation events to an agent using Server-Sent Events.
+// Clients resume after a reconnect with the Last-Event-ID header or the last_event_id
+// query parameter, and may limit the stream to some conversations or event types.
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Find where to resume from
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		var err error
+		lastID, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid last event ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Subscribe before sending anything so no event is lost in between
+	filter := eventFilter(splitList(r.URL.Query().Get("conversations")), splitList(r.URL.Query().Get("types")))
+	sub, backlog, missed := inbox.Subscribe(lastID, filter)
+	defer inbox.Unsubscribe(sub)
+
+	agentID := r.URL.Query().Get("agent_id")
+	log.Printf("Agent %q subscribed to events from %d", agentID, lastID)
+	defer log.Printf("Agent %q unsubscribed from events", agentID)
+
+	// Start the event stream
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell the client to reload its view when events it needs are gone
+	if missed {
+		if err := writeEvent(w, models.Event{Type: models.EventResync, Timestamp: time.Now()}); err != nil {
+			return
+		}
+	}
+	for _, event := range backlog {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// The client fell behind; it reconnects and resumes from its last event
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// eventFilter matches events of the given conversations and types; empty lists match everything
+func eventFilter(conversations, types []string) events.Filter {
+	if len(conversations) == 0 && len(types) == 0 {
+		return nil
+	}
+	return func(event models.Event) bool {
+		return matchesAny(conversations, event.UserID) && matchesAny(types, event.Type)
+	}
+}
+
+// matchesAny reports whether value is in list, treating an empty list as matching everything
+func matchesAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated query parameter
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// writeEvent writes an event in Server-Sent Events format; events without an ID don't move the client's resume point
+func writeEvent(w http.ResponseWriter, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.ID != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+	return err
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/command"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/flow"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// startFlowTool is the LLM service tool the assistant starts flows with
+const startFlowTool = "start_flow"
+
+// Flow settings
+var (
+	// flows are the forms users fill in step by step; nil when not configured
+	flows *flow.Registry
+	// flowResults posts completed forms to their webhooks
+	flowResults *flow.Notifier
+)
+
+// setupFlows loads the flow definitions and registers the commands that start and cancel them
+func setupFlows(cfg *config.Config, client *whatsapp.Client) error {
+	if cfg.FlowsFile == "" {
+		return nil
+	}
+
+	registry, err := flow.Load(cfg.FlowsFile)
+	if err != nil {
+		return err
+	}
+	flows = registry
+	flowResults = flow.NewNotifier(cfg.FlowWebhookURL, time.Duration(cfg.FlowWebhookTimeout)*time.Second)
+
+	for _, f := range registry.Flows() {
+		if f.Command == "" {
+			continue
+		}
+		name := f.Name
+		if err := commands.Register(command.Command{
+			Name:        f.Command,
+			Aliases:     f.Aliases,
+			Keywords:    f.Keywords,
+			Description: f.Description,
+			Handler: func(ctx context.Context, req *command.Request) (string, error) {
+				return "", startFlow(client, req.Session, name)
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	return commands.Register(command.Command{
+		Name:        "cancel",
+		Aliases:     []string{"batal"},
+		Keywords:    []string{"cancel", "batal"},
+		Description: "command.cancel",
+		Handler:     cancelCommand,
+	})
+}
+
+// startFlow starts a flow for the user, abandoning any other, and asks its first question
+func startFlow(client *whatsapp.Client, sess *models.Session, name string) error {
+	if flows == nil {
+		return flow.ErrNotFound
+	}
+	state, step, err := flows.Start(name, time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Started flow %s for %s", name, sess.UserID)
+	sess.Flow = state
+	askStep(client, sess, step, "")
+	return nil
+}
+
+// handleFlow passes the message to the flow the user is filling in.
+// It returns false when there is none. The caller saves the session.
+func handleFlow(client *whatsapp.Client, message models.Message, sess *models.Session) bool {
+	if sess.Flow == nil {
+		return false
+	}
+	if flows == nil {
+		sess.Flow = nil
+		return false
+	}
+
+	step, err := flows.Answer(sess.Flow, message)
+	switch {
+	case errors.Is(err, flow.ErrInvalidAnswer):
+		// Explain the problem and ask again
+		problem := step.Error
+		if problem == "" {
+			problem = i18n.T(languageOf(sess), "flow.invalid."+step.Type)
+		}
+		askStep(client, sess, step, problem)
+		return true
+	case err != nil:
+		// The flow was changed or removed since the user started it
+		log.Printf("Abandoned flow of %s: %v", sess.UserID, err)
+		sess.Flow = nil
+		return false
+	case step == nil:
+		completeFlow(client, sess)
+		return true
+	}
+
+	askStep(client, sess, step, "")
+	return true
+}
+
+// askStep sends a flow question, after a problem with the previous answer if there was one
+func askStep(client *whatsapp.Client, sess *models.Session, step *flow.Step, problem string) {
+	text := step.Prompt
+	if problem != "" {
+		text = problem + "\n\n" + text
+	}
+
+	var err error
+	if len(step.Buttons) > 0 {
+		err = client.SendButtons(sess.UserID, text, step.Buttons)
+	} else {
+		err = client.SendMessage(sess.UserID, text)
+	}
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+		return
+	}
+	recordMessage(sess, models.SenderBot, "", text)
+}
+
+// completeFlow thanks the user and posts their answers to the flow's webhook
+func completeFlow(client *whatsapp.Client, sess *models.Session) {
+	state := sess.Flow
+	sess.Flow = nil
+
+	f, ok := flows.Get(state.Name)
+	if !ok {
+		return
+	}
+	result := models.FlowResult{
+		Flow:        state.Name,
+		UserID:      sess.UserID,
+		Answers:     state.Answers,
+		StartedAt:   state.StartedAt,
+		CompletedAt: time.Now(),
+	}
+	log.Printf("Completed flow %s for %s", state.Name, sess.UserID)
+
+	text := f.Completion
+	if text == "" {
+		text = i18n.T(languageOf(sess), "flow.completed")
+	}
+	if err := client.SendMessage(sess.UserID, text); err != nil {
+		log.Printf("Failed to send message: %v", err)
+	} else {
+		recordMessage(sess, models.SenderBot, "", text)
+	}
+
+	go func() {
+		if err := flowResults.Post(context.Background(), f, result); err != nil {
+			log.Printf("Failed to post flow %s of %s: %v", result.Flow, result.UserID, err)
+		}
+	}()
+}
+
+// cancelCommand stops the flow the user is filling in
+func cancelCommand(ctx context.Context, req *command.Request) (string, error) {
+	if req.Session.Flow == nil {
+		return i18n.T(req.Language, "flow.none"), nil
+	}
+	log.Printf("Cancelled flow %s for %s", req.Session.Flow.Name, req.Session.UserID)
+	req.Session.Flow = nil
+	return i18n.T(req.Language, "flow.cancelled"), nil
+}
+
+// flowSummaries lists the flows the assistant may start, or nil without flows
+func flowSummaries() []models.FlowSummary {
+	if flows == nil {
+		return nil
+	}
+	return flows.Summaries()
+}
+
+// requestedFlow returns the flow the assistant started with a tool call, if any
+func requestedFlow(toolCalls []models.ToolCall) string {
+	name := ""
+	for _, call := range toolCalls {
+		if call.Name != startFlowTool || call.Error != "" {
+			continue
+		}
+		var args struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(call.Arguments), &args); err == nil {
+			name = args.Name
+		}
+	}
+	return name
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// Handoff reasons
+const (
+	handoffUserRequest = "user_request"
+	handoffBotFailed   = "bot_failed"
+	handoffAgent       = "agent"
+)
+
+// Handoff settings
+var (
+	// handoffTimeout returns conversations to the bot after this long without messages; zero disables it
+	handoffTimeout time.Duration
+	// handoffAfterFailures hands a conversation to an agent after this many consecutive bot failures; zero disables it
+	handoffAfterFailures int
+)
+
+// startHandoff pauses automatic replies so an agent can take over the conversation. Outside
+// business hours, handoffs not started by an agent are queued until the business opens.
+func startHandoff(sess *models.Session, reason string) {
+	if sess.Mode == models.SessionModeHuman {
+		return
+	}
+
+	now := time.Now()
+	if reason != handoffAgent && !businessOpen(now) {
+		if sess.Mode == models.SessionModeQueued {
+			return
+		}
+		sess.Mode = models.SessionModeQueued
+		sess.HandoffReason = reason
+		sess.HandoffAt = now
+		log.Printf("Handoff of %s queued until opening (%s)", sess.UserID, reason)
+
+		publish(models.EventHandoffQueued, sess.UserID, models.Handoff{
+			UserID:        sess.UserID,
+			Mode:          sess.Mode,
+			Reason:        reason,
+			Since:         sess.HandoffAt,
+			LastMessageAt: sess.LastMessageAt,
+		})
+		return
+	}
+
+	// A queued handoff keeps its place in line
+	if sess.Mode != models.SessionModeQueued {
+		sess.HandoffAt = now
+	}
+	sess.Mode = models.SessionModeHuman
+	sess.HandoffReason = reason
+	sess.LastMessageAt = now
+	log.Printf("Conversation with %s handed to an agent (%s)", sess.UserID, reason)
+
+	publish(models.EventHandoffStarted, sess.UserID, models.Handoff{
+		UserID:        sess.UserID,
+		Mode:          sess.Mode,
+		Reason:        reason,
+		Since:         sess.HandoffAt,
+		LastMessageAt: sess.LastMessageAt,
+	})
+}
+
+// endHandoff sets the conversation's mode, returning it to the bot or closing it
+func endHandoff(sess *models.Session, mode string) {
+	sess.Mode = mode
+	sess.HandoffReason = ""
+	sess.HandoffAt = time.Time{}
+	sess.Failures = 0
+	log.Printf("Conversation with %s is now in %s mode", sess.UserID, mode)
+
+	publish(models.EventHandoffEnded, sess.UserID, models.Handoff{
+		UserID:        sess.UserID,
+		Mode:          mode,
+		LastMessageAt: sess.LastMessageAt,
+	})
+}
+
+// handoffExpired reports whether a handoff has been inactive for longer than the timeout
+func handoffExpired(sess *models.Session, now time.Time) bool {
+	return sess.Mode == models.SessionModeHuman && handoffTimeout > 0 && now.Sub(sess.LastMessageAt) > handoffTimeout
+}
+
+// notify sends a localized notice to the session's user and records it in the thread
+func notify(client *whatsapp.Client, sess *models.Session, key string, args ...interface{}) {
+	text := i18n.T(languageOf(sess), key, args...)
+	if err := client.SendMessage(sess.UserID, text); err != nil {
+		log.Printf("Failed to send message: %v", err)
+		return
+	}
+	recordMessage(sess, models.SenderBot, "", text)
+}
+
+// watchHandoffs returns inactive handoffs to the bot and passes queued handoffs to agents
+// once the business opens, until ctx is cancelled
+func watchHandoffs(ctx context.Context, client *whatsapp.Client, interval time.Duration) {
+	if handoffTimeout <= 0 && businessHours == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			all, err := sessions.List(ctx)
+			if err != nil {
+				log.Printf("Failed to list sessions: %v", err)
+				continue
+			}
+			now := time.Now()
+			for _, sess := range all {
+				switch {
+				case handoffExpired(sess, now):
+					expireHandoff(ctx, client, sess.UserID)
+				case sess.Mode == models.SessionModeQueued && businessOpen(now):
+					dequeueHandoff(ctx, client, sess.UserID)
+				}
+			}
+		}
+	}
+}
+
+// expireHandoff returns an inactive handoff to the bot and tells the user
+func expireHandoff(ctx context.Context, client *whatsapp.Client, userID string) {
+	unlock := lockUser(userID)
+	defer unlock()
+
+	// Check again now that the session is locked; a message may have just arrived
+	sess, err := loadSession(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load session for %s: %v", userID, err)
+		return
+	}
+	if !handoffExpired(sess, time.Now()) {
+		return
+	}
+
+	endHandoff(sess, models.SessionModeBot)
+	notify(client, sess, "handoff.ended")
+	if err := saveSession(ctx, sess); err != nil {
+		log.Printf("Failed to save session for %s: %v", userID, err)
+	}
+}
+
+// dequeueHandoff hands a queued conversation to the agents now that the business is open
+func dequeueHandoff(ctx context.Context, client *whatsapp.Client, userID string) {
+	unlock := lockUser(userID)
+	defer unlock()
+
+	sess, err := loadSession(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load session for %s: %v", userID, err)
+		return
+	}
+	if sess.Mode != models.SessionModeQueued || !businessOpen(time.Now()) {
+		return
+	}
+
+	startHandoff(sess, sess.HandoffReason)
+	notify(client, sess, "handoff.dequeued")
+	if err := saveSession(ctx, sess); err != nil {
+		log.Printf("Failed to save session for %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/hours"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// openingLayout formats opening times in notices
+const openingLayout = "2006-01-02 15:04 MST"
+
+// Business hours settings
+var (
+	// businessHours is the team's calendar; nil means the team is always available
+	businessHours *hours.Calendar
+	// closedMessage replaces the localized out-of-hours notice when set
+	closedMessage string
+)
+
+// setupBusinessHours loads the business hours calendar, if one is configured
+func setupBusinessHours(cfg *config.Config) error {
+	closedMessage = cfg.BusinessClosedMessage
+	if cfg.BusinessHours == "" {
+		return nil
+	}
+
+	// The hours are in the business's timezone, which defaults to the users' default timezone
+	loc := defaultTimezone
+	if cfg.BusinessTimezone != "" {
+		var err error
+		if loc, err = loadTimezone(cfg.BusinessTimezone); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	businessHours, err = hours.Parse(cfg.BusinessHours, cfg.BusinessHolidays, loc)
+	return err
+}
+
+// businessOpen reports whether the team is available at the given time
+func businessOpen(now time.Time) bool {
+	return businessHours == nil || businessHours.IsOpen(now)
+}
+
+// businessStatus returns the team's availability for the LLM prompt, or nil without business hours
+func businessStatus(now time.Time) *models.BusinessHours {
+	if businessHours == nil {
+		return nil
+	}
+	return businessHours.Status(now)
+}
+
+// openingTime formats when the team is next available, in the user's timezone
+func openingTime(sess *models.Session, now time.Time) string {
+	if businessHours == nil {
+		return ""
+	}
+	opens := businessHours.NextOpen(now)
+	if opens.IsZero() {
+		return ""
+	}
+	return opens.In(timezoneOf(sess)).Format(openingLayout)
+}
+
+// closedNotice tells the user the team is away, once per closed period
+func closedNotice(client *whatsapp.Client, sess *models.Session, now time.Time) {
+	if businessOpen(now) || now.Before(sess.ClosedNoticeUntil) {
+		return
+	}
+
+	text := closedMessage
+	if text == "" {
+		text = i18n.T(languageOf(sess), "hours.closed", openingTime(sess, now))
+	}
+	if err := client.SendMessage(sess.UserID, text); err != nil {
+		log.Printf("Failed to send message: %v", err)
+		return
+	}
+	recordMessage(sess, models.SenderBot, "", text)
+
+	// Stay quiet until the next opening; if there is none, remind the user a day later
+	sess.ClosedNoticeUntil = businessHours.NextOpen(now)
+	if sess.ClosedNoticeUntil.IsZero() {
+		sess.ClosedNoticeUntil = now.Add(24 * time.Hour)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+)
+
+// idempotencyTTL is how long a completed request is remembered
+const idempotencyTTL = 24 * time.Hour
+
+// idempotencyInFlightTTL is how long a key stays claimed by a request that never finished,
+// e.g. because the service stopped while sending
+const idempotencyInFlightTTL = 5 * time.Minute
+
+// idempotencySweepInterval is how often expired keys are forgotten
+const idempotencySweepInterval = 10 * time.Minute
+
+// idempotencyEntry remembers a request made with an idempotency key
+type idempotencyEntry struct {
+	hash    [32]byte
+	done    bool
+	status  int
+	body    []byte
+	expires time.Time
+}
+
+// idempotencyRecord is a completed entry as it is persisted
+type idempotencyRecord struct {
+	Key     string          `json:"key"`
+	Hash    string          `json:"hash"`
+	Status  int             `json:"status"`
+	Body    json.RawMessage `json:"body"`
+	Expires time.Time       `json:"expires"`
+}
+
+// idempotencyCache remembers successful responses by idempotency key so retried requests
+// are answered without sending the message again. Completed responses are also written to
+// dir, when set, so they survive restarts and are shared by instances using the same
+// directory; requests in flight are only known to the instance handling them.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	dir     *jsonfile.Dir
+}
+
+// setupIdempotency creates the outbound idempotency cache, persisted when
+// IDEMPOTENCY_STORE_DIR is set, and starts forgetting expired keys
+func setupIdempotency(cfg *config.Config) error {
+	var dir *jsonfile.Dir
+	if cfg.IdempotencyStoreDir != "" {
+		d, err := jsonfile.NewDir(cfg.IdempotencyStoreDir)
+		if err != nil {
+			return fmt.Errorf("failed to create idempotency store directory: %w", err)
+		}
+		dir = d
+	}
+
+	outbound = newIdempotencyCache(dir)
+	go outbound.run(context.Background(), idempotencySweepInterval)
+	return nil
+}
+
+// newIdempotencyCache creates an empty cache, persisted in dir unless it is nil
+func newIdempotencyCache(dir *jsonfile.Dir) *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotencyEntry), dir: dir}
+}
+
+// fileKey is the file name of an idempotency key, which may contain any characters
+func fileKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// begin claims a key for a request body. It returns the remembered entry when the key
+// was already used; the entry is not done while the first request is still in flight.
+func (c *idempotencyCache) begin(key string, body []byte) (entry *idempotencyEntry, claimed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if e, ok := c.entries[key]; ok && now.Before(e.expires) {
+		return e, false
+	}
+	if e := c.load(key, now); e != nil {
+		c.entries[key] = e
+		return e, false
+	}
+
+	e := &idempotencyEntry{hash: sha256.Sum256(body), expires: now.Add(idempotencyInFlightTTL)}
+	c.entries[key] = e
+	return e, true
+}
+
+// load reads a completed entry from the store, if it has one that has not expired
+func (c *idempotencyCache) load(key string, now time.Time) *idempotencyEntry {
+	if c.dir == nil {
+		return nil
+	}
+
+	var record idempotencyRecord
+	found, err := c.dir.Get(fileKey(key), &record)
+	if err != nil {
+		log.Printf("Failed to read idempotency key: %v", err)
+		return nil
+	}
+	if !found || record.Key != key || !now.Before(record.Expires) {
+		return nil
+	}
+
+	e := &idempotencyEntry{done: true, status: record.Status, body: record.Body, expires: record.Expires}
+	if hash, err := hex.DecodeString(record.Hash); err == nil {
+		copy(e.hash[:], hash)
+	}
+	return e
+}
+
+// matches reports whether the entry was created for the same request body
+func (e *idempotencyEntry) matches(body []byte) bool {
+	return e.hash == sha256.Sum256(body)
+}
+
+// finish remembers the response to a claimed key
+func (c *idempotencyCache) finish(key string, status int, body []byte) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	e.done = true
+	e.status = status
+	e.body = body
+	e.expires = time.Now().Add(idempotencyTTL)
+	record := idempotencyRecord{Key: key, Hash: hex.EncodeToString(e.hash[:]), Status: status, Body: body, Expires: e.expires}
+	c.mu.Unlock()
+
+	if c.dir != nil {
+		if err := c.dir.Put(fileKey(key), record); err != nil {
+			log.Printf("Failed to save idempotency key: %v", err)
+		}
+	}
+}
+
+// release forgets a claimed key so the request can be retried. Finished keys are kept, so
+// it can be deferred as soon as a key is claimed.
+func (c *idempotencyCache) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok && !e.done {
+		delete(c.entries, key)
+	}
+}
+
+// run forgets expired keys at every interval until ctx is cancelled
+func (c *idempotencyCache) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.sweep(time.Now())
+		}
+	}
+}
+
+// sweep forgets the keys that expired before now, in memory and in the store
+func (c *idempotencyCache) sweep(now time.Time) {
+	c.mu.Lock()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.dir == nil {
+		return
+	}
+	records, err := jsonfile.List[idempotencyRecord](c.dir)
+	if err != nil {
+		log.Printf("Failed to list idempotency keys: %v", err)
+		return
+	}
+	for _, record := range records {
+		if !now.Before(record.Expires) {
+			if err := c.dir.Delete(fileKey(record.Key)); err != nil {
+				log.Printf("Failed to delete idempotency key: %v", err)
+			}
+		}
+	}
+}
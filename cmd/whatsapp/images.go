@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// imageTimeout bounds downloading an image
+const imageTimeout = 30 * time.Second
+
+// imagePlaceholder marks an image in the thread, ahead of its caption
+const imagePlaceholder = "[image]"
+
+// imageAttachments downloads the image of an image message so it can be sent to the LLM.
+// It returns nothing for other messages.
+func imageAttachments(ctx context.Context, client *whatsapp.Client, message models.Message) ([]models.Attachment, error) {
+	if message.Media == nil || message.Media.Type != models.MediaImage {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, imageTimeout)
+	defer cancel()
+
+	media, err := client.DownloadMedia(ctx, message.Media.ID)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := media.MIMEType
+	if mimeType == "" {
+		mimeType = message.Media.MIMEType
+	}
+	return []models.Attachment{{
+		Type:     models.MediaImage,
+		MIMEType: mimeType,
+		Data:     media.Data,
+	}}, nil
+}
+
+// imageFailed tells the user their image couldn't be looked at
+func imageFailed(client *whatsapp.Client, sess *models.Session, err error) {
+	log.Printf("Failed to download image from %s: %v", sess.UserID, err)
+	if errors.Is(err, whatsapp.ErrMediaTooLarge) {
+		notify(client, sess, "image.too_large")
+		return
+	}
+	notify(client, sess, "image.failed")
+}
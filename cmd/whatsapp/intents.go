@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/intent"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// intentRouter answers fixed questions before the LLM is called; nil when not configured
+var intentRouter *intent.Router
+
+// setupIntentRouter loads the intent rules and watches the file for changes unless reloading is disabled
+func setupIntentRouter(cfg *config.Config) error {
+	if cfg.IntentRulesFile == "" {
+		return nil
+	}
+
+	router, err := intent.NewRouter(cfg.IntentRulesFile)
+	if err != nil {
+		return err
+	}
+	intentRouter = router
+
+	if cfg.IntentReloadInterval <= 0 {
+		log.Printf("Intent rules are not reloaded (INTENT_RELOAD_SECONDS=%d)", cfg.IntentReloadInterval)
+		return nil
+	}
+	go router.Watch(context.Background(), time.Duration(cfg.IntentReloadInterval)*time.Second)
+	return nil
+}
+
+// sendIntentReply sends the canned reply of an intent rule
+func sendIntentReply(client *whatsapp.Client, to string, reply intent.Reply) error {
+	switch reply.Type {
+	case intent.ReplyButtons:
+		return client.SendButtons(to, reply.Text, reply.Buttons)
+	case intent.ReplyTemplate:
+		return client.SendTemplate(to, reply.Template.Name, reply.Template.Language, reply.Template.Parameters)
+	default:
+		return client.SendMessage(to, reply.Text)
+	}
+}
+
+// answerWithIntent replies with the canned answer of a matched intent rule
+func answerWithIntent(client *whatsapp.Client, sess *models.Session, rule *intent.Rule) {
+	log.Printf("Message from %s matched intent %s", sess.UserID, rule.Name)
+	if rule.Reply.Type == intent.ReplyFlow {
+		if err := startFlow(client, sess, rule.Reply.Flow); err != nil {
+			log.Printf("Failed to start flow %s: %v", rule.Reply.Flow, err)
+		}
+		return
+	}
+	if err := sendIntentReply(client, sess.UserID, rule.Reply); err != nil {
+		log.Printf("Failed to send intent reply: %v", err)
+		return
+	}
+
+	text := rule.Reply.Text
+	if rule.Reply.Type == intent.ReplyTemplate {
+		text = "[template " + rule.Reply.Template.Name + "]"
+	}
+	recordMessage(sess, models.SenderBot, "", text)
+}
@@ -12,16 +12,24 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed timezone data, which the container image lacks
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/events"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/session"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
 )
 
-// LLM service URL
-var llmServiceURL string
+// LLM service settings
+var (
+	llmServiceURL   string
+	llmServiceToken string
+	streamReplies   bool
+)
 
 func main() {
 	// Load configuration
@@ -29,69 +37,172 @@ func main() {
 	
 	// Set LLM service URL
 	llmServiceURL = getEnv("LLM_SERVICE_URL", "http://llm-service:8082")
+	llmServiceToken = cfg.LLMServiceToken
+	streamReplies = getEnv("STREAM_REPLIES", "false") == "true"
 
 	// Create WhatsApp client
 	whatsappClient := whatsapp.NewClient(cfg)
 
+	// Initialize the per-user session store
+	var err error
+	sessions, err = session.NewStore(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize session store: %v", err)
+	}
+	defaultLanguage = cfg.DefaultLanguage
+	detectLanguages = cfg.LanguageDetection
+	if defaultTimezone, err = loadTimezone(cfg.DefaultTimezone); err != nil {
+		log.Fatalf("Invalid default timezone: %v", err)
+	}
+
+	// Publish conversation events to agents
+	inbox = events.NewBroker(cfg.EventBufferSize)
+
+	// Load the team's business hours
+	if err := setupBusinessHours(cfg); err != nil {
+		log.Fatalf("Invalid business hours: %v", err)
+	}
+
+	// Return inactive handoffs to the bot
+	handoffTimeout = time.Duration(cfg.HandoffTimeout) * time.Minute
+	handoffAfterFailures = cfg.HandoffAfterFailures
+	go watchHandoffs(context.Background(), whatsappClient, time.Minute)
+
+	// Send text outside the service window as the fallback template, if any
+	setupWindow(cfg)
+
+	// Answer retried outbound requests without sending again
+	if err := setupIdempotency(cfg); err != nil {
+		log.Fatalf("Failed to set up idempotency store: %v", err)
+	}
+
+	// Refuse to message numbers that opted out
+	if err := setupConsent(cfg, whatsappClient); err != nil {
+		log.Fatalf("Failed to set up consent store: %v", err)
+	}
+
+	// Send scheduled messages and reminders
+	if err := setupScheduler(cfg, whatsappClient); err != nil {
+		log.Fatalf("Failed to set up scheduler: %v", err)
+	}
+
+	// Send scheduled broadcast campaigns
+	if err := setupCampaigns(cfg, whatsappClient); err != nil {
+		log.Fatalf("Failed to set up campaigns: %v", err)
+	}
+
+	// Register the slash commands
+	if err := setupCommands(cfg); err != nil {
+		log.Fatalf("Failed to register commands: %v", err)
+	}
+
+	// Load the forms users fill in step by step
+	if err := setupFlows(cfg, whatsappClient); err != nil {
+		log.Fatalf("Failed to load flows: %v", err)
+	}
+
+	// Load the intent rules answered without the LLM
+	if err := setupIntentRouter(cfg); err != nil {
+		log.Fatalf("Failed to load intent rules: %v", err)
+	}
+
+	// Transcribe voice notes
+	if err := setupTranscription(cfg); err != nil {
+		log.Fatalf("Failed to set up transcription: %v", err)
+	}
+
+	// Keep documents users share as context for their questions
+	setupDocuments(cfg)
+
+	// Speak replies to users who prefer voice notes
+	if err := setupSpeech(cfg); err != nil {
+		log.Fatalf("Failed to set up speech synthesis: %v", err)
+	}
+
 	// Create router
 	r := chi.NewRouter()
 
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
-
-	// Routes
-	r.Get("/webhook", func(w http.ResponseWriter, r *http.Request) {
-		// Extract the challenge parameter
-		challenge := r.URL.Query().Get("hub.challenge")
-		mode := r.URL.Query().Get("hub.mode")
-		token := r.URL.Query().Get("hub.verify_token")
-
-		// Verify the token
-		if mode == "subscribe" && token == cfg.WhatsAppToken {
-			// Return the challenge
-			w.Write([]byte(challenge))
-			return
-		}
 
-		// Otherwise return an error
-		http.Error(w, "Invalid request", http.StatusBadRequest)
-	})
+	// Request/response routes
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(60 * time.Second))
 
-	r.Post("/webhook", func(w http.ResponseWriter, r *http.Request) {
-		// Decode the webhook data
-		var webhookData models.WhatsAppWebhookRequest
-		if err := json.NewDecoder(r.Body).Decode(&webhookData); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
+		r.Get("/webhook", func(w http.ResponseWriter, r *http.Request) {
+			// Extract the challenge parameter
+			challenge := r.URL.Query().Get("hub.challenge")
+			mode := r.URL.Query().Get("hub.mode")
+			token := r.URL.Query().Get("hub.verify_token")
 
-		// Process the webhook data
-		messages, err := whatsappClient.ProcessWebhook(&webhookData)
-		if err != nil {
-			http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
-			return
-		}
+			// Verify the token
+			if mode == "subscribe" && token == cfg.WhatsAppToken {
+				// Return the challenge
+				w.Write([]byte(challenge))
+				return
+			}
 
-		// Process each message
-		for _, message := range messages {
-			log.Printf("Received message from %s: %s", message.From, message.Text)
-			
-			// Process the message asynchronously
-			go processMessage(whatsappClient, message)
-		}
+			// Otherwise return an error
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+		})
 
-		// Return a success response
-		w.WriteHeader(http.StatusOK)
-	})
+		r.Post("/webhook", func(w http.ResponseWriter, r *http.Request) {
+			// Decode the webhook data
+			var webhookData models.WhatsAppWebhookRequest
+			if err := json.NewDecoder(r.Body).Decode(&webhookData); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			// Process the webhook data
+			messages, err := whatsappClient.ProcessWebhook(&webhookData)
+			if err != nil {
+				http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+				return
+			}
+
+			// Process each message
+			for _, message := range messages {
+				log.Printf("Received message from %s: %s", message.From, message.Text)
+
+				// Process the message asynchronously
+				go processMessage(whatsappClient, message)
+			}
+
+			// Track campaign deliveries and pass statuses on to agents
+			for _, status := range whatsappClient.ProcessStatuses(&webhookData) {
+				campaigns.HandleStatus(status)
+				publish(models.EventStatus, status.RecipientID, status)
+			}
+
+			// Return a success response
+			w.WriteHeader(http.StatusOK)
+		})
+
+		// Messages sent by other systems
+		r.With(auth.RequireToken(cfg.OutboundAPIToken)).Post("/messages", outboundHandler(whatsappClient))
+		r.With(auth.RequireToken(cfg.OutboundAPIToken)).Get("/windows/{userID}", windowHandler)
+
+		// Scheduled messages and reminders
+		r.Mount("/schedules", scheduleRoutes(cfg))
 
-	// Health check endpoint
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		// Broadcast campaign administration
+		r.Mount("/campaigns", campaignRoutes(cfg))
+
+		// Opt-in and opt-out records
+		r.Mount("/consents", consentRoutes(cfg))
+
+		// Health check endpoint
+		r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		})
 	})
 
+	// Human agent API
+	r.Mount("/agent", agentRoutes(cfg, whatsappClient))
+
 	// Start server
 	server := &http.Server{
 		Addr:    ":" + cfg.Port,
@@ -125,60 +236,224 @@ func main() {
 
 // processMessage calls the LLM service and sends the response back to the user
 func processMessage(client *whatsapp.Client, message models.Message) {
+	ctx := context.Background()
+
+	// Handle one message per user at a time so replies stay in order
+	unlock := lockUser(message.From)
+	defer unlock()
+
+	// Load the user's session and record the message in its thread
+	sess, loadErr := loadSession(ctx, message.From)
+	if loadErr != nil {
+		log.Printf("Failed to load session for %s: %v", message.From, loadErr)
+	}
+	defer func() {
+		// Don't overwrite a session that couldn't be read
+		if loadErr != nil {
+			return
+		}
+		if err := saveSession(ctx, sess); err != nil {
+			log.Printf("Failed to save session for %s: %v", message.From, err)
+		}
+	}()
+
+	// Understand voice notes as the text spoken in them
+	voiceErr := transcribeVoice(ctx, client, &message)
+
+	// Return inactive handoffs to the bot; a closed conversation reopens with the bot
+	if handoffExpired(sess, time.Now()) {
+		endHandoff(sess, models.SessionModeBot)
+		notify(client, sess, "handoff.ended")
+	} else if sess.Mode == models.SessionModeClosed {
+		endHandoff(sess, models.SessionModeBot)
+	}
+	recordInbound(sess, message)
+
+	// Tell the user what was heard, or that the voice note couldn't be understood
+	if voiceErr != nil {
+		voiceFailed(client, sess, voiceErr)
+		return
+	}
+	echoTranscript(client, sess, message)
+
+	// Follow the language the user writes in
+	detectLanguage(sess, message)
+
+	// Handle stop and start keywords before anything else
+	if handleConsent(ctx, client, message, sess) {
+		return
+	}
+
+	// Stay silent to users who opted out; agents still see their messages
+	optedOut, err := consents.OptedOut(ctx, message.From)
+	if err != nil || optedOut {
+		log.Printf("Message from %s not answered: opted out %v, error %v", message.From, optedOut, err)
+		return
+	}
+
+	// Handle slash commands and their keywords
+	if handleCommand(ctx, client, message, sess) {
+		return
+	}
+
+	// Leave the message for the agent while the conversation is handed off
+	if sess.Mode == models.SessionModeHuman {
+		log.Printf("Message from %s left for an agent", message.From)
+		return
+	}
+
+	// Take the answer to the form the user is filling in
+	if handleFlow(client, message, sess) {
+		return
+	}
+
+	// Tell the user when the team is away
+	closedNotice(client, sess, time.Now())
+
+	// Read a document the user shared; without a question in the caption, just confirm it
+	doc, err := shareDocument(ctx, client, sess, message)
+	if err != nil {
+		documentFailed(client, sess, message, err)
+		return
+	}
+	if doc != nil && message.Text == "" {
+		notify(client, sess, "document.received", doc.Name)
+		return
+	}
+
+	// Answer fixed questions without calling the LLM
+	if intentRouter != nil {
+		if rule, ok := intentRouter.Match(message); ok {
+			answerWithIntent(client, sess, rule)
+			return
+		}
+	}
+
+	// Fetch the image the user sent so the assistant can look at it
+	attachments, err := imageAttachments(ctx, client, message)
+	if err != nil {
+		imageFailed(client, sess, err)
+		return
+	}
+
 	// Create a request to the LLM service
 	llmRequest := models.LLMRequest{
-		UserID:      message.From,
-		MessageText: message.Text,
+		UserID:        message.From,
+		MessageText:   message.Text,
+		Language:      languageOf(sess),
+		Timezone:      timezoneOf(sess).String(),
+		BusinessHours: businessStatus(time.Now()),
+		Flows:         flowSummaries(),
+		Attachments:   attachments,
+		Documents:     activeDocuments(sess, time.Now()),
 	}
 
+	// Generate the reply, streaming long answers to the user in parts when enabled.
+	// Voice replies are spoken whole, so they are never streamed.
+	var reply string
+	var toolCalls []models.ToolCall
+	voice := speaksReply(sess, message)
+	if streamReplies && !voice {
+		reply, toolCalls, err = streamReply(client, message.From, llmRequest)
+	} else {
+		reply, toolCalls, err = generateReply(llmRequest)
+		if err == nil {
+			if voice {
+				err = sendSpoken(ctx, client, sess, reply)
+			} else {
+				err = client.SendMessage(message.From, reply)
+			}
+			if err != nil {
+				log.Printf("Failed to send message: %v", err)
+				return
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Failed to generate LLM response: %v", err)
+		replyFailed(client, sess)
+		return
+	}
+
+	sess.Failures = 0
+	recordMessage(sess, models.SenderBot, "", reply)
+
+	// Start the form the assistant chose for the user
+	if name := requestedFlow(toolCalls); name != "" {
+		if err := startFlow(client, sess, name); err != nil {
+			log.Printf("Failed to start flow %s: %v", name, err)
+		}
+	}
+}
+
+// generateReply calls the LLM service and returns the response text with the tools it called
+func generateReply(llmRequest models.LLMRequest) (string, []models.ToolCall, error) {
 	// Convert to JSON
 	jsonBody, err := json.Marshal(llmRequest)
 	if err != nil {
-		log.Printf("Failed to marshal LLM request: %v", err)
-		return
+		return "", nil, fmt.Errorf("failed to marshal LLM request: %w", err)
 	}
 
 	// Send the request to the LLM service
-	resp, err := http.Post(
-		llmServiceURL+"/generate",
-		"application/json",
-		bytes.NewBuffer(jsonBody),
-	)
+	req, err := newLLMRequest(context.Background(), http.MethodPost, "/generate", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		log.Printf("Failed to call LLM service: %v", err)
-		
-		// Send an error message to the user
-		client.SendMessage(message.From, "Sorry, I'm having trouble processing your message right now.")
-		return
+		return "", nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to call LLM service: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read the response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("Failed to read LLM response: %v", err)
-		return
+		return "", nil, fmt.Errorf("failed to read LLM response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("LLM service returned status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
 	}
 
 	// Parse the response
 	var llmResponse models.LLMResponse
 	if err := json.Unmarshal(body, &llmResponse); err != nil {
-		log.Printf("Failed to unmarshal LLM response: %v", err)
-		return
+		return "", nil, fmt.Errorf("failed to unmarshal LLM response: %w", err)
 	}
 
 	// Check for errors
 	if llmResponse.Error != "" {
-		log.Printf("LLM error: %s", llmResponse.Error)
-		client.SendMessage(message.From, "Sorry, I couldn't generate a response for your message.")
-		return
+		return "", nil, fmt.Errorf("LLM error: %s", llmResponse.Error)
 	}
 
-	// Send the response back to the user
-	err = client.SendMessage(message.From, llmResponse.ResponseText)
+	return llmResponse.ResponseText, llmResponse.ToolCalls, nil
+}
+
+// newLLMRequest creates a request to the LLM service, authenticated with the service token
+func newLLMRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, llmServiceURL+path, body)
 	if err != nil {
-		log.Printf("Failed to send message: %v", err)
+		return nil, fmt.Errorf("failed to create LLM request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+llmServiceToken)
+	return req, nil
+}
+
+// replyFailed apologizes to the user, handing the conversation to an agent once the bot keeps failing
+func replyFailed(client *whatsapp.Client, sess *models.Session) {
+	sess.Failures++
+	if handoffAfterFailures > 0 && sess.Failures >= handoffAfterFailures && sess.Mode != models.SessionModeQueued {
+		startHandoff(sess, handoffBotFailed)
+		if sess.Mode == models.SessionModeQueued {
+			notify(client, sess, "handoff.queued", openingTime(sess, time.Now()))
+		} else {
+			notify(client, sess, "handoff.started")
+		}
+		return
 	}
+	notify(client, sess, "reply.failed")
 }
 
 // Helper function to get environment variable with a default value
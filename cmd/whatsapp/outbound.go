@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/consent"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// maxOutboundRequestSize limits the size of outbound message requests
+const maxOutboundRequestSize = 1 << 20
+
+// outbound remembers the responses of outbound requests by idempotency key
+var outbound *idempotencyCache
+
+// outboundHandler sends a message requested by another system and returns its message ID.
+// Requests with the same Idempotency-Key header are only sent once.
+func outboundHandler(client *whatsapp.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxOutboundRequestSize))
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		var request models.OutboundMessageRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+			return
+		}
+
+		// Sessions are keyed by the digits-only number webhooks carry, so "+62 812-..." and
+		// "62812..." are the same recipient, and the same request for idempotency
+		request.To = whatsapp.NormalizePhone(request.To)
+		normalized, err := json.Marshal(request)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse("invalid request body"))
+			return
+		}
+
+		// Answer retries from the remembered response
+		key := r.Header.Get("Idempotency-Key")
+		if key != "" {
+			entry, claimed := outbound.begin(key, normalized)
+			if !claimed {
+				switch {
+				case !entry.matches(normalized):
+					http.Error(w, "Idempotency key was used for a different request", http.StatusUnprocessableEntity)
+				case !entry.done:
+					http.Error(w, "A request with this idempotency key is in progress", http.StatusConflict)
+				default:
+					w.Header().Set("Content-Type", "application/json")
+					w.Header().Set("Idempotent-Replayed", "true")
+					w.WriteHeader(entry.status)
+					w.Write(entry.body)
+				}
+				return
+			}
+			// Failed sends, and requests that panic, can be retried with the same key
+			defer outbound.release(key)
+		}
+
+		status, response := sendOutbound(r.Context(), client, &request)
+
+		// Only successful sends are remembered
+		payload, _ := json.Marshal(response)
+		if key != "" && status == http.StatusOK {
+			outbound.finish(key, status, payload)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(payload)
+	}
+}
+
+// sendOutbound validates and sends an outbound message to a normalized number, returning the
+// response status and body
+func sendOutbound(ctx context.Context, client *whatsapp.Client, request *models.OutboundMessageRequest) (int, interface{}) {
+	reqBody, summary, err := buildOutbound(request)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(err.Error())
+	}
+
+	// Free-form messages need a recent message from the user; text can fall back to a template
+	response := models.OutboundMessageResponse{To: request.To}
+	if request.Type != "template" {
+		sess, err := sessions.Get(ctx, request.To)
+		if err != nil {
+			log.Printf("Failed to load session for %s: %v", request.To, err)
+			return http.StatusInternalServerError, errorResponse("failed to load session")
+		}
+		if window := windowOf(sess, time.Now()); !window.Open {
+			fallback := fallbackMessage(request.To, request.Text)
+			if request.Type != "text" || fallback == nil {
+				return http.StatusUnprocessableEntity, errorResponse(windowError(window).Error())
+			}
+			reqBody = fallback
+			summary = "[template " + fallbackTemplate + "] " + request.Text
+			response.FallbackTemplate = fallbackTemplate
+		}
+	}
+
+	messageID, err := client.Send(reqBody)
+	if errors.Is(err, consent.ErrOptedOut) {
+		return http.StatusUnprocessableEntity, errorResponse(err.Error())
+	}
+	if err != nil {
+		log.Printf("Failed to send outbound message to %s: %v", request.To, err)
+		return http.StatusBadGateway, errorResponse("failed to send message")
+	}
+
+	// Record the message without waiting for a reply being generated for the same user
+	go recordOutbound(request.To, summary)
+
+	response.MessageID = messageID
+	return http.StatusOK, response
+}
+
+// buildOutbound validates an outbound request and builds the WhatsApp message
+// and a text summary for the user's thread
+func buildOutbound(request *models.OutboundMessageRequest) (*models.WhatsAppSendMessageRequest, string, error) {
+	if request.To == "" {
+		return nil, "", errors.New("to is required")
+	}
+
+	switch request.Type {
+	case "text":
+		if strings.TrimSpace(request.Text) == "" {
+			return nil, "", errors.New("text is required")
+		}
+		return whatsapp.TextMessage(request.To, request.Text), request.Text, nil
+
+	case "template":
+		tpl := request.Template
+		if tpl == nil || tpl.Name == "" || tpl.Language == "" {
+			return nil, "", errors.New("template name and language are required")
+		}
+		return whatsapp.TemplateMessage(request.To, tpl.Name, tpl.Language, tpl.Parameters), "[template " + tpl.Name + "]", nil
+
+	case "image", "document", "audio", "video":
+		if request.Media == nil || (request.Media.ID == "") == (request.Media.Link == "") {
+			return nil, "", errors.New("media requires exactly one of id or link")
+		}
+		reqBody, err := whatsapp.MediaMessage(request.To, request.Type, request.Media)
+		if err != nil {
+			return nil, "", err
+		}
+		return reqBody, strings.TrimSpace(fmt.Sprintf("[%s] %s", request.Type, request.Media.Caption)), nil
+
+	case "interactive":
+		interactive := request.Interactive
+		if interactive == nil || interactive.Body.Text == "" {
+			return nil, "", errors.New("interactive body text is required")
+		}
+		switch interactive.Type {
+		case "button":
+			if n := len(interactive.Action.Buttons); n == 0 || n > 3 {
+				return nil, "", errors.New("button messages need one to three buttons")
+			}
+		case "list":
+			if interactive.Action.Button == "" || len(interactive.Action.Sections) == 0 {
+				return nil, "", errors.New("list messages need a button label and at least one section")
+			}
+		default:
+			return nil, "", errors.New("interactive type must be button or list")
+		}
+		return &models.WhatsAppSendMessageRequest{
+			To:          request.To,
+			Type:        "interactive",
+			Interactive: interactive,
+		}, interactive.Body.Text, nil
+
+	default:
+		return nil, "", errors.New("type must be one of text, template, image, document, audio, video or interactive")
+	}
+}
+
+// recordOutbound adds a message sent through the outbound API to the user's thread
+func recordOutbound(userID, text string) {
+	ctx := context.Background()
+	unlock := lockUser(userID)
+	defer unlock()
+
+	sess, err := loadSession(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load session for %s: %v", userID, err)
+		return
+	}
+	recordMessage(sess, models.SenderSystem, "", text)
+	if err := saveSession(ctx, sess); err != nil {
+		log.Printf("Failed to save session for %s: %v", userID, err)
+	}
+}
+
+// errorResponse is the JSON body of a failed outbound request
+func errorResponse(message string) map[string]string {
+	return map[string]string{"error": message}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/auth"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/scheduler"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// schedules sends scheduled messages and reminders
+var schedules *scheduler.Scheduler
+
+// setupScheduler loads the stored scheduled messages and starts sending them when due
+func setupScheduler(cfg *config.Config, client *whatsapp.Client) error {
+	store, err := scheduler.NewStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	retention := time.Duration(cfg.SchedulerRetention) * time.Hour
+	schedules, err = scheduler.NewScheduler(ctx, store, retention, func(ctx context.Context, msg *models.ScheduledMessage) (string, error) {
+		return sendScheduled(ctx, client, msg)
+	})
+	if err != nil {
+		return err
+	}
+	go schedules.Run(ctx)
+	return nil
+}
+
+// sendScheduled sends a scheduled message, as the fallback template once the service window
+// has closed, and records it in the user's thread
+func sendScheduled(ctx context.Context, client *whatsapp.Client, msg *models.ScheduledMessage) (string, error) {
+	// Messages stored before numbers were normalized may still be formatted
+	userID := whatsapp.NormalizePhone(msg.UserID)
+	unlock := lockUser(userID)
+	defer unlock()
+
+	sess, err := loadSession(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	reqBody := whatsapp.TextMessage(userID, msg.Text)
+	if window := windowOf(sess, time.Now()); !window.Open {
+		if reqBody = fallbackMessage(userID, msg.Text); reqBody == nil {
+			return "", windowError(window)
+		}
+	}
+
+	messageID, err := client.Send(reqBody)
+	if err != nil {
+		return "", err
+	}
+	recordMessage(sess, models.SenderBot, "", msg.Text)
+	if err := saveSession(ctx, sess); err != nil {
+		log.Printf("Failed to save session for %s: %v", userID, err)
+	}
+	return messageID, nil
+}
+
+// userIDParam returns the normalized ?user_id= filter; ok is false for a value with no digits,
+// which must not be mistaken for no filter
+func userIDParam(r *http.Request) (userID string, ok bool) {
+	raw := r.URL.Query().Get("user_id")
+	userID = whatsapp.NormalizePhone(raw)
+	return userID, raw == "" || userID != ""
+}
+
+// scheduleRoutes returns the scheduled message API
+func scheduleRoutes(cfg *config.Config) http.Handler {
+	r := chi.NewRouter()
+	r.Use(auth.RequireToken(cfg.OutboundAPIToken))
+
+	// List scheduled messages, optionally filtered by user and status
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDParam(r)
+		if !ok {
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+		messages := schedules.List(userID, r.URL.Query().Get("status"))
+		if messages == nil {
+			messages = []*models.ScheduledMessage{}
+		}
+		writeJSON(w, http.StatusOK, messages)
+	})
+
+	// Schedule a message
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+		var request models.ScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if request.Source == "" {
+			request.Source = models.ScheduleSourceAPI
+		}
+		// Sessions, locks and windows are keyed by the digits-only number
+		request.UserID = whatsapp.NormalizePhone(request.UserID)
+
+		// Read local times in the requested timezone, or else the user's
+		loc := defaultTimezone
+		if request.Timezone != "" {
+			var err error
+			if loc, err = loadTimezone(request.Timezone); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		} else if request.UserID != "" {
+			sess, err := sessions.Get(r.Context(), request.UserID)
+			if err != nil {
+				http.Error(w, "Failed to load session", http.StatusInternalServerError)
+				return
+			}
+			loc = timezoneOf(sess)
+		}
+
+		sendAt, err := scheduler.ParseTime(request.SendAt, loc)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msg, err := schedules.Schedule(r.Context(), request.UserID, request.Text, sendAt, loc, request.Source)
+		writeSchedule(w, http.StatusCreated, msg, err)
+	})
+
+	// Get a scheduled message
+	r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		msg, ok := schedules.Get(chi.URLParam(r, "id"))
+		if !ok {
+			http.Error(w, scheduler.ErrNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, msg)
+	})
+
+	// Cancel a pending message; with ?user_id= it must belong to that user
+	r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDParam(r)
+		if !ok {
+			http.Error(w, "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+		msg, err := schedules.Cancel(r.Context(), chi.URLParam(r, "id"), userID)
+		writeSchedule(w, http.StatusOK, msg, err)
+	})
+
+	return r
+}
+
+// writeSchedule writes a scheduled message, or maps a scheduler error to its HTTP status
+func writeSchedule(w http.ResponseWriter, status int, msg *models.ScheduledMessage, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, scheduler.ErrNotPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, scheduler.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		log.Printf("Scheduled message request failed: %v", err)
+		http.Error(w, "Failed to save scheduled message", http.StatusInternalServerError)
+	default:
+		writeJSON(w, status, msg)
+	}
+}
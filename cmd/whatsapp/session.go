@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/command"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/keylock"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/session"
+)
+
+// maxThreadMessages is the number of recent messages kept in a session's thread
+const maxThreadMessages = 100
+
+// Session settings
+var (
+	// sessions stores each user's state in the WhatsApp service
+	sessions session.Store
+	// defaultLanguage is used for users who haven't chosen a language and whose language wasn't detected
+	defaultLanguage string
+	// detectLanguages follows the language users write in when they haven't chosen one
+	detectLanguages bool
+	// defaultTimezone is used for users who haven't set a timezone
+	defaultTimezone = time.UTC
+)
+
+// timezoneAliases maps common abbreviations to IANA timezone names
+var timezoneAliases = map[string]string{
+	"wib":  "Asia/Jakarta",
+	"wita": "Asia/Makassar",
+	"wit":  "Asia/Jayapura",
+}
+
+// userLocks serializes the handling of each user's session
+var userLocks = keylock.New()
+
+// lockUser locks a user's session until the returned function is called
+func lockUser(userID string) func() {
+	return userLocks.Lock(userID)
+}
+
+// loadSession returns the user's session, or a fresh one if it can't be read
+func loadSession(ctx context.Context, userID string) (*models.Session, error) {
+	sess, err := sessions.Get(ctx, userID)
+	if err != nil {
+		return &models.Session{UserID: userID}, err
+	}
+	return sess, nil
+}
+
+// saveSession stores the user's session
+func saveSession(ctx context.Context, sess *models.Session) error {
+	sess.UpdatedAt = time.Now()
+	return sessions.Save(ctx, sess)
+}
+
+// recordMessage appends a message to the session's thread, dropping the oldest beyond the limit,
+// and publishes it to connected agents
+func recordMessage(sess *models.Session, sender, agentID, text string) {
+	message := models.ThreadMessage{
+		Sender:    sender,
+		AgentID:   agentID,
+		Text:      text,
+		Timestamp: time.Now(),
+	}
+	sess.Thread = append(sess.Thread, message)
+	if len(sess.Thread) > maxThreadMessages {
+		sess.Thread = sess.Thread[len(sess.Thread)-maxThreadMessages:]
+	}
+	sess.LastMessageAt = message.Timestamp
+
+	publish(models.EventMessage, sess.UserID, message)
+}
+
+// recordInbound records a message from the user, which opens their service window
+// from the time WhatsApp received it
+func recordInbound(sess *models.Session, message models.Message) {
+	recordMessage(sess, models.SenderUser, "", threadText(message))
+
+	// Webhooks can arrive out of order, so never move the window backwards
+	if message.Timestamp.After(sess.LastInboundAt) {
+		sess.LastInboundAt = message.Timestamp
+	}
+}
+
+// threadText returns how a message from the user reads in the thread, marking images and
+// documents so agents know one was sent
+func threadText(message models.Message) string {
+	if message.Media == nil {
+		return message.Text
+	}
+	switch message.Media.Type {
+	case models.MediaImage:
+		return strings.TrimSpace(imagePlaceholder + " " + message.Text)
+	case models.MediaDocument:
+		return strings.TrimSpace(fmt.Sprintf("[document: %s] %s", documentName(message.Media.Filename, message.Media.MIMEType), message.Text))
+	}
+	return message.Text
+}
+
+// languageOf returns the language replies to the session's user are written in
+func languageOf(sess *models.Session) string {
+	if sess.Language != "" {
+		return sess.Language
+	}
+	if sess.DetectedLanguage != "" {
+		return sess.DetectedLanguage
+	}
+	return defaultLanguage
+}
+
+// detectLanguage remembers the language of a message, which replies follow until the user
+// chooses one with /lang. Commands and messages too short to tell are ignored.
+func detectLanguage(sess *models.Session, message models.Message) {
+	if !detectLanguages || strings.HasPrefix(message.Text, command.Prefix) {
+		return
+	}
+	if lang := i18n.Detect(message.Text); lang != "" {
+		sess.DetectedLanguage = lang
+	}
+}
+
+// timezoneOf returns the timezone of the session's user
+func timezoneOf(sess *models.Session) *time.Location {
+	if sess.Timezone != "" {
+		if loc, err := loadTimezone(sess.Timezone); err == nil {
+			return loc
+		}
+	}
+	return defaultTimezone
+}
+
+// loadTimezone loads an IANA timezone or one of the timezone aliases
+func loadTimezone(name string) (*time.Location, error) {
+	if alias, ok := timezoneAliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/command"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/tts"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// speechTimeout bounds synthesizing and uploading a voice reply
+const speechTimeout = 2 * time.Minute
+
+// errReplyTooLong is returned for replies too long to be sent as a voice note
+var errReplyTooLong = errors.New("reply is too long to speak")
+
+// formatting removes WhatsApp formatting marks, which speech synthesizers would read aloud
+var formatting = strings.NewReplacer("*", "", "~", "", "`", "")
+
+// Speech synthesis settings
+var (
+	// synthesizer speaks replies; nil when voice replies are disabled
+	synthesizer tts.Synthesizer
+	// speechMaxChars is the longest reply sent as a voice note; longer ones are sent as text
+	speechMaxChars int
+	// defaultReplyMode applies to users who haven't chosen one
+	defaultReplyMode string
+)
+
+// setupSpeech creates the configured speech synthesizer
+func setupSpeech(cfg *config.Config) error {
+	switch cfg.TTSReplyMode {
+	case models.ReplyModeText, models.ReplyModeVoice, models.ReplyModeAuto:
+	default:
+		return fmt.Errorf("unknown reply mode %q", cfg.TTSReplyMode)
+	}
+
+	s, err := tts.New(cfg)
+	if err != nil {
+		return err
+	}
+	synthesizer = s
+	speechMaxChars = cfg.TTSMaxChars
+	defaultReplyMode = cfg.TTSReplyMode
+	return nil
+}
+
+// replyModeOf returns how the session's user wants to be answered
+func replyModeOf(sess *models.Session) string {
+	if sess.ReplyMode != "" {
+		return sess.ReplyMode
+	}
+	return defaultReplyMode
+}
+
+// speaksReply reports whether the reply to a message should be a voice note
+func speaksReply(sess *models.Session, message models.Message) bool {
+	if synthesizer == nil {
+		return false
+	}
+	switch replyModeOf(sess) {
+	case models.ReplyModeVoice:
+		return true
+	case models.ReplyModeAuto:
+		return message.Media != nil && message.Media.Type == models.MediaAudio
+	default:
+		return false
+	}
+}
+
+// sendSpoken sends a reply as a voice note, falling back to text when it can't be spoken
+func sendSpoken(ctx context.Context, client *whatsapp.Client, sess *models.Session, text string) error {
+	err := speak(ctx, client, sess, text)
+	if err == nil {
+		return nil
+	}
+	log.Printf("Failed to send voice reply to %s, sending text instead: %v", sess.UserID, err)
+	return client.SendMessage(sess.UserID, text)
+}
+
+// speak synthesizes the text, uploads the audio and sends it as a voice note
+func speak(ctx context.Context, client *whatsapp.Client, sess *models.Session, text string) error {
+	text = strings.TrimSpace(formatting.Replace(text))
+	if speechMaxChars > 0 && len([]rune(text)) > speechMaxChars {
+		return errReplyTooLong
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, speechTimeout)
+	defer cancel()
+
+	audio, err := synthesizer.Synthesize(ctx, text, languageOf(sess))
+	if err != nil {
+		return err
+	}
+	mediaID, err := client.UploadMedia(ctx, audio, tts.MIMEType, "reply.ogg")
+	if err != nil {
+		return err
+	}
+	reqBody, err := whatsapp.MediaMessage(sess.UserID, "audio", &models.WhatsAppMedia{ID: mediaID})
+	if err != nil {
+		return err
+	}
+	_, err = client.Send(reqBody)
+	return err
+}
+
+// voiceCommand shows or changes whether the user is answered with voice notes
+func voiceCommand(ctx context.Context, req *command.Request) (string, error) {
+	if synthesizer == nil {
+		return i18n.T(req.Language, "speech.disabled"), nil
+	}
+	if len(req.Args) == 0 {
+		return i18n.T(req.Language, "speech.current."+replyModeOf(req.Session)), nil
+	}
+
+	var mode string
+	switch strings.ToLower(req.Args[0]) {
+	case "on", "voice", "ya", "suara":
+		mode = models.ReplyModeVoice
+	case "off", "text", "tidak", "teks":
+		mode = models.ReplyModeText
+	case "auto", "otomatis":
+		mode = models.ReplyModeAuto
+	default:
+		return i18n.T(req.Language, "speech.usage"), nil
+	}
+	req.Session.ReplyMode = mode
+	return i18n.T(req.Language, "speech.changed."+mode), nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// minStreamPartLength is the minimum length of a partial reply sent while streaming
+const minStreamPartLength = 300
+
+// streamReply streams a response from the LLM service and sends it to the user in parts,
+// splitting at paragraph breaks so the first part of a long answer arrives early.
+// It returns the text that was sent and the tools the assistant called.
+func streamReply(client *whatsapp.Client, to string, llmRequest models.LLMRequest) (string, []models.ToolCall, error) {
+	// Convert to JSON
+	jsonBody, err := json.Marshal(llmRequest)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal LLM request: %w", err)
+	}
+
+	// Send the request to the streaming endpoint
+	req, err := newLLMRequest(context.Background(), http.MethodPost, "/generate/stream", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to call LLM service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("LLM service returned status code %d", resp.StatusCode)
+	}
+
+	var pending strings.Builder
+	var sent []string
+
+	// send delivers a part of the reply to the user
+	send := func(text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if err := client.SendMessage(to, text); err != nil {
+			log.Printf("Failed to send message: %v", err)
+			return
+		}
+		sent = append(sent, text)
+	}
+
+	// Read the event stream
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	event := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+			continue
+		case !strings.HasPrefix(line, "data: "):
+			continue
+		}
+		data := []byte(strings.TrimPrefix(line, "data: "))
+
+		switch event {
+		case "chunk":
+			var chunk models.LLMStreamChunk
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				return strings.Join(sent, "\n\n"), nil, fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+			}
+			pending.WriteString(chunk.Text)
+
+			// Send everything up to the last paragraph break once enough text has arrived
+			text := pending.String()
+			if idx := strings.LastIndex(text, "\n\n"); idx >= minStreamPartLength {
+				send(text[:idx])
+				pending.Reset()
+				pending.WriteString(text[idx:])
+			}
+		case "done":
+			var llmResponse models.LLMResponse
+			json.Unmarshal(data, &llmResponse)
+
+			send(pending.String())
+			return strings.Join(sent, "\n\n"), llmResponse.ToolCalls, nil
+		case "error":
+			var llmResponse models.LLMResponse
+			json.Unmarshal(data, &llmResponse)
+
+			// Keep the parts the user already received
+			if len(sent) > 0 {
+				log.Printf("LLM error: %s", llmResponse.Error)
+				return strings.Join(sent, "\n\n"), nil, nil
+			}
+			return "", nil, fmt.Errorf("LLM error: %s", llmResponse.Error)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return strings.Join(sent, "\n\n"), nil, fmt.Errorf("failed to read LLM stream: %w", err)
+	}
+
+	// The stream ended without a final event; deliver what arrived
+	send(pending.String())
+	return strings.Join(sent, "\n\n"), nil, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/transcribe"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// voiceTimeout bounds downloading and transcribing a voice note
+const voiceTimeout = 2 * time.Minute
+
+// voicePlaceholder stands for a voice note in the thread when it couldn't be transcribed
+const voicePlaceholder = "[voice note]"
+
+// errVoiceUnsupported is returned for voice notes when transcription is disabled
+var errVoiceUnsupported = errors.New("voice notes are not transcribed")
+
+// Transcription settings
+var (
+	// transcriber turns voice notes into text; nil when transcription is disabled
+	transcriber transcribe.Transcriber
+	// echoTranscripts tells users what was understood from their voice notes
+	echoTranscripts bool
+)
+
+// setupTranscription creates the configured transcriber
+func setupTranscription(cfg *config.Config) error {
+	t, err := transcribe.New(cfg)
+	if err != nil {
+		return err
+	}
+	transcriber = t
+	echoTranscripts = cfg.TranscribeEcho
+	return nil
+}
+
+// transcribeVoice replaces the text of an audio message with the words spoken in it.
+// It does nothing for other messages.
+func transcribeVoice(ctx context.Context, client *whatsapp.Client, message *models.Message) error {
+	if message.Media == nil || message.Media.Type != models.MediaAudio {
+		return nil
+	}
+	message.Text = voicePlaceholder
+	if transcriber == nil {
+		return errVoiceUnsupported
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, voiceTimeout)
+	defer cancel()
+
+	media, err := client.DownloadMedia(ctx, message.Media.ID)
+	if err != nil {
+		return err
+	}
+	text, err := transcriber.Transcribe(ctx, media.Data, media.MIMEType)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Transcribed voice note from %s: %s", message.From, text)
+	message.Text = text
+	return nil
+}
+
+// voiceFailed tells the user their voice note couldn't be understood, unless an agent has the conversation
+func voiceFailed(client *whatsapp.Client, sess *models.Session, err error) {
+	log.Printf("Failed to transcribe voice note from %s: %v", sess.UserID, err)
+	if sess.Mode == models.SessionModeHuman {
+		return
+	}
+
+	switch {
+	case errors.Is(err, errVoiceUnsupported):
+		notify(client, sess, "voice.unsupported")
+	case errors.Is(err, whatsapp.ErrMediaTooLarge):
+		notify(client, sess, "voice.too_long")
+	default:
+		notify(client, sess, "voice.failed")
+	}
+}
+
+// echoTranscript tells the user what was understood from their voice note, when enabled
+func echoTranscript(client *whatsapp.Client, sess *models.Session, message models.Message) {
+	if !echoTranscripts || message.Media == nil || message.Media.Type != models.MediaAudio {
+		return
+	}
+	if sess.Mode == models.SessionModeHuman {
+		return
+	}
+	notify(client, sess, "voice.heard", message.Text)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// serviceWindow is how long after a user's last message free-form messages may be sent;
+// outside it only templates are delivered
+const serviceWindow = 24 * time.Hour
+
+// errOutsideWindow is returned for free-form messages to users outside the service window
+var errOutsideWindow = errors.New("the 24-hour customer service window is closed; only template messages can be sent")
+
+// Fallback template for text sent outside the service window
+var (
+	fallbackTemplate string
+	fallbackLanguage string
+)
+
+// setupWindow configures the template text is sent as once a user's service window has closed
+func setupWindow(cfg *config.Config) {
+	fallbackTemplate = cfg.OutboundFallbackTemplate
+	fallbackLanguage = cfg.OutboundFallbackLanguage
+}
+
+// windowOf returns the state of the session's service window at the given time
+func windowOf(sess *models.Session, now time.Time) models.ServiceWindow {
+	window := models.ServiceWindow{UserID: sess.UserID}
+	if sess.LastInboundAt.IsZero() {
+		return window
+	}
+
+	lastInbound := sess.LastInboundAt
+	expiresAt := lastInbound.Add(serviceWindow)
+	window.LastInboundAt = &lastInbound
+	window.ExpiresAt = &expiresAt
+	window.Open = now.Before(expiresAt)
+	return window
+}
+
+// windowError explains why a free-form message can't be sent in a closed window
+func windowError(window models.ServiceWindow) error {
+	if window.ExpiresAt == nil {
+		return fmt.Errorf("%w (the user has never messaged us)", errOutsideWindow)
+	}
+	return fmt.Errorf("%w (it closed at %s)", errOutsideWindow, window.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// fallbackMessage returns the fallback template carrying the text, or nil if none is configured
+func fallbackMessage(to, text string) *models.WhatsAppSendMessageRequest {
+	if fallbackTemplate == "" {
+		return nil
+	}
+	return whatsapp.TemplateMessage(to, fallbackTemplate, fallbackLanguage, []string{text})
+}
+
+// windowHandler returns the state of a user's service window. The number may be formatted,
+// e.g. "+62 812-...", since sessions are looked up by its digits.
+func windowHandler(w http.ResponseWriter, r *http.Request) {
+	userID := phoneParam(r, "userID")
+	if userID == "" {
+		http.Error(w, "Invalid phone number", http.StatusBadRequest)
+		return
+	}
+	sess, err := sessions.Get(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to load session for %s: %v", userID, err)
+		http.Error(w, "Failed to load session", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, windowOf(sess, time.Now()))
+}
+
+// phoneParam returns a phone number URL parameter with its formatting removed, so that
+// "+62 812-..." finds the same session as "62812..."; it is empty when there are no digits
+func phoneParam(r *http.Request, name string) string {
+	value := chi.URLParam(r, name)
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		value = unescaped
+	}
+	return whatsapp.NormalizePhone(value)
+}
@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/go-chi/chi/v5 v5.0.10
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/tmc/langchaingo v0.1.4
 )
 
@@ -43,10 +43,12 @@ if [ -z "$WHATSAPP_PHONE_ID" ] || [ "$WHATSAPP_PHONE_ID" = "your_whatsapp_phone_
     exit 1
 fi
 
-if [ -z "$OPENROUTER_API_KEY" ] || [ "$OPENROUTER_API_KEY" = "your_openrouter_api_key" ]; then
-    echo "❌ OPENROUTER_API_KEY is not set in .env file."
-    echo "⚠️ Please edit the .env file with your credentials before proceeding."
-    exit 1
+if [ "${LLM_PROVIDER:-openrouter}" = "openrouter" ]; then
+    if [ -z "$OPENROUTER_API_KEY" ] || [ "$OPENROUTER_API_KEY" = "your_openrouter_api_key" ]; then
+        echo "❌ OPENROUTER_API_KEY is not set in .env file."
+        echo "⚠️ Please edit the .env file with your credentials before proceeding."
+        exit 1
+    fi
 fi
 
 echo "✅ Environment variables check passed."
@@ -0,0 +1,561 @@
+package campaign
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/consent"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// Campaign errors
+var (
+	ErrNotFound     = errors.New("campaign not found")
+	ErrInvalidState = errors.New("campaign cannot be changed in its current status")
+	ErrNoRecipients = errors.New("campaign has no recipients")
+	ErrInvalid      = errors.New("invalid campaign")
+)
+
+const (
+	// tickInterval is how often the manager sends the next batch of messages
+	tickInterval = time.Second
+	// maxSendAttempts is how many times a recipient is tried before a temporary failure becomes final
+	maxSendAttempts = 5
+	// retryDelay is the wait before the first retry; it doubles with each attempt up to maxRetryDelay
+	retryDelay    = 30 * time.Second
+	maxRetryDelay = 30 * time.Minute
+)
+
+// statusRank orders delivery statuses so late webhooks never move a recipient backwards
+var statusRank = map[string]int{
+	models.RecipientPending:   0,
+	models.RecipientSent:      1,
+	models.RecipientDelivered: 2,
+	models.RecipientRead:      3,
+}
+
+// Sender sends WhatsApp messages and returns their message IDs
+type Sender interface {
+	Send(reqBody *models.WhatsAppSendMessageRequest) (string, error)
+}
+
+// recipientRef locates a recipient within a campaign
+type recipientRef struct {
+	campaignID string
+	index      int
+}
+
+// sendItem is a message picked for sending
+type sendItem struct {
+	ref     recipientRef
+	request *models.WhatsAppSendMessageRequest
+}
+
+// Manager runs campaigns, throttling sends and tracking each recipient's delivery status
+type Manager struct {
+	store         Store
+	sender        Sender
+	ratePerSecond int
+	dailyLimit    int
+
+	// saveMu keeps store writes in the order their snapshots were taken
+	saveMu sync.Mutex
+
+	mu        sync.Mutex
+	campaigns map[string]*models.Campaign
+	// messages maps sent message IDs to their recipients
+	messages map[string]recipientRef
+	// sentTimes are the times of sends in the last 24 hours, for the daily limit
+	sentTimes []time.Time
+	// dirty maps campaigns changed since the last save to the indexes of their changed recipients
+	dirty map[string]map[int]bool
+	// compact holds finished campaigns to save in full once more
+	compact map[string]bool
+}
+
+// NewManager creates a manager and loads the stored campaigns
+func NewManager(ctx context.Context, cfg *config.Config, store Store, sender Sender) (*Manager, error) {
+	m := &Manager{
+		store:         store,
+		sender:        sender,
+		ratePerSecond: cfg.CampaignRatePerSecond,
+		dailyLimit:    cfg.CampaignDailyLimit,
+		campaigns:     make(map[string]*models.Campaign),
+		messages:      make(map[string]recipientRef),
+		dirty:         make(map[string]map[int]bool),
+		compact:       make(map[string]bool),
+	}
+	if m.ratePerSecond <= 0 {
+		m.ratePerSecond = 1
+	}
+
+	campaigns, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaigns: %w", err)
+	}
+
+	// Rebuild the message index and the sends counted against the daily limit
+	since := time.Now().Add(-24 * time.Hour)
+	for _, c := range campaigns {
+		m.campaigns[c.ID] = c
+		for i, r := range c.Recipients {
+			if r.MessageID != "" {
+				m.messages[r.MessageID] = recipientRef{campaignID: c.ID, index: i}
+			}
+			if r.SentAt.After(since) {
+				m.sentTimes = append(m.sentTimes, r.SentAt)
+			}
+		}
+	}
+	sort.Slice(m.sentTimes, func(i, j int) bool { return m.sentTimes[i].Before(m.sentTimes[j]) })
+
+	return m, nil
+}
+
+// Create creates a campaign, scheduling it when a start time is given
+func (m *Manager) Create(ctx context.Context, request *models.CampaignRequest) (*models.Campaign, error) {
+	if request.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if request.Template.Name == "" || request.Template.Language == "" {
+		return nil, fmt.Errorf("%w: template name and language are required", ErrInvalid)
+	}
+
+	now := time.Now()
+	c := &models.Campaign{
+		ID:        newID(),
+		Name:      request.Name,
+		Template:  request.Template,
+		Status:    models.CampaignDraft,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	addRecipients(c, request.Recipients)
+
+	if request.StartAt != nil {
+		if len(c.Recipients) == 0 {
+			return nil, ErrNoRecipients
+		}
+		c.Status = models.CampaignScheduled
+		c.StartAt = *request.StartAt
+	}
+	updateCounts(c)
+
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	m.campaigns[c.ID] = c
+	result := copyCampaign(c)
+	m.mu.Unlock()
+
+	return result, m.store.Save(ctx, result)
+}
+
+// AddRecipients adds recipients to a campaign that hasn't started; duplicates are ignored
+func (m *Manager) AddRecipients(ctx context.Context, id string, recipients []models.Recipient) (*models.Campaign, error) {
+	return m.update(ctx, id, func(c *models.Campaign) error {
+		if c.Status != models.CampaignDraft && c.Status != models.CampaignScheduled {
+			return ErrInvalidState
+		}
+		addRecipients(c, recipients)
+		return nil
+	})
+}
+
+// Schedule starts a draft campaign at startAt, or now when startAt is zero
+func (m *Manager) Schedule(ctx context.Context, id string, startAt time.Time) (*models.Campaign, error) {
+	return m.update(ctx, id, func(c *models.Campaign) error {
+		if c.Status != models.CampaignDraft && c.Status != models.CampaignScheduled {
+			return ErrInvalidState
+		}
+		if len(c.Recipients) == 0 {
+			return ErrNoRecipients
+		}
+		if startAt.IsZero() {
+			startAt = time.Now()
+		}
+		c.Status = models.CampaignScheduled
+		c.StartAt = startAt
+		return nil
+	})
+}
+
+// Pause stops sending until the campaign is resumed
+func (m *Manager) Pause(ctx context.Context, id string) (*models.Campaign, error) {
+	return m.update(ctx, id, func(c *models.Campaign) error {
+		if c.Status != models.CampaignScheduled && c.Status != models.CampaignRunning {
+			return ErrInvalidState
+		}
+		c.Status = models.CampaignPaused
+		return nil
+	})
+}
+
+// Resume continues a paused campaign, waiting for its start time if it hasn't passed
+func (m *Manager) Resume(ctx context.Context, id string) (*models.Campaign, error) {
+	return m.update(ctx, id, func(c *models.Campaign) error {
+		if c.Status != models.CampaignPaused {
+			return ErrInvalidState
+		}
+		c.Status = models.CampaignScheduled
+		return nil
+	})
+}
+
+// Cancel stops a campaign for good; recipients not yet messaged are skipped
+func (m *Manager) Cancel(ctx context.Context, id string) (*models.Campaign, error) {
+	return m.update(ctx, id, func(c *models.Campaign) error {
+		if c.Status == models.CampaignCompleted || c.Status == models.CampaignCancelled {
+			return ErrInvalidState
+		}
+		now := time.Now()
+		for i := range c.Recipients {
+			if c.Recipients[i].Status == models.RecipientPending {
+				c.Recipients[i].Status = models.RecipientSkipped
+				c.Recipients[i].UpdatedAt = now
+			}
+		}
+		c.Status = models.CampaignCancelled
+		c.CompletedAt = now
+		return nil
+	})
+}
+
+// Get returns a campaign with its recipients
+func (m *Manager) Get(id string) (*models.Campaign, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.campaigns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyCampaign(c), nil
+}
+
+// List returns every campaign without its recipients, newest first
+func (m *Manager) List() []*models.Campaign {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	campaigns := make([]*models.Campaign, 0, len(m.campaigns))
+	for _, c := range m.campaigns {
+		summary := *c
+		summary.Recipients = nil
+		campaigns = append(campaigns, &summary)
+	}
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt.After(campaigns[j].CreatedAt)
+	})
+	return campaigns
+}
+
+// HandleStatus applies a delivery status from the webhook to the recipient it belongs to.
+// It returns false when the message wasn't sent by a campaign.
+func (m *Manager) HandleStatus(status models.MessageStatus) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ref, ok := m.messages[status.MessageID]
+	if !ok {
+		return false
+	}
+	c := m.campaigns[ref.campaignID]
+	r := &c.Recipients[ref.index]
+
+	switch {
+	case status.Status == models.RecipientFailed:
+		r.Status = models.RecipientFailed
+		r.Error = status.Error
+	case r.Status == models.RecipientFailed:
+		// A failure is final
+	case statusRank[status.Status] > statusRank[r.Status]:
+		r.Status = status.Status
+	default:
+		return true
+	}
+	r.UpdatedAt = time.Now()
+	updateCounts(c)
+	m.markDirty(c.ID, ref.index)
+	return true
+}
+
+// Run sends scheduled campaigns until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, item := range m.nextBatch(time.Now()) {
+				messageID, err := m.sender.Send(item.request)
+				m.recordSend(item.ref, messageID, err)
+				// The rest of the batch would likely hit the same rate limit or outage
+				if whatsapp.IsRetryable(err) {
+					break
+				}
+			}
+			m.saveDirty(ctx)
+		}
+	}
+}
+
+// nextBatch starts due campaigns and picks the next messages allowed by the rate and daily limits
+func (m *Manager) nextBatch(now time.Time) []sendItem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Forget sends older than the daily window
+	since := now.Add(-24 * time.Hour)
+	for len(m.sentTimes) > 0 && m.sentTimes[0].Before(since) {
+		m.sentTimes = m.sentTimes[1:]
+	}
+	budget := m.ratePerSecond
+	if m.dailyLimit > 0 && m.dailyLimit-len(m.sentTimes) < budget {
+		budget = m.dailyLimit - len(m.sentTimes)
+	}
+
+	// Serve campaigns in the order they started
+	var active []*models.Campaign
+	for _, c := range m.campaigns {
+		if c.Status == models.CampaignScheduled && !c.StartAt.After(now) {
+			c.Status = models.CampaignRunning
+			c.UpdatedAt = now
+			m.markDirty(c.ID)
+			log.Printf("Campaign %s (%s) started", c.ID, c.Name)
+		}
+		if c.Status == models.CampaignRunning {
+			active = append(active, c)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].StartAt.Before(active[j].StartAt) })
+
+	var batch []sendItem
+	for _, c := range active {
+		pending := false
+		for i, r := range c.Recipients {
+			if r.Status != models.RecipientPending {
+				continue
+			}
+			pending = true
+			if r.NextAttemptAt.After(now) {
+				continue
+			}
+			if len(batch) >= budget {
+				break
+			}
+			batch = append(batch, sendItem{
+				ref:     recipientRef{campaignID: c.ID, index: i},
+				request: whatsapp.TemplateMessage(r.Phone, c.Template.Name, c.Template.Language, r.Variables),
+			})
+		}
+
+		// Every recipient has been messaged
+		if !pending {
+			c.Status = models.CampaignCompleted
+			c.CompletedAt = now
+			c.UpdatedAt = now
+			m.markDirty(c.ID)
+			m.compact[c.ID] = true
+			log.Printf("Campaign %s (%s) completed", c.ID, c.Name)
+		}
+	}
+	return batch
+}
+
+// recordSend stores the outcome of sending a message to a recipient
+func (m *Manager) recordSend(ref recipientRef, messageID string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	c := m.campaigns[ref.campaignID]
+	r := &c.Recipients[ref.index]
+	r.UpdatedAt = now
+	switch {
+	case errors.Is(err, consent.ErrOptedOut):
+		// Opted-out numbers are skipped rather than counted as failures
+		r.Status = models.RecipientSkipped
+		r.Error = err.Error()
+	case whatsapp.IsRetryable(err) && r.Attempts+1 < maxSendAttempts:
+		// Rate limits and outages leave the recipient pending until its next attempt
+		r.Attempts++
+		r.Error = err.Error()
+		r.NextAttemptAt = now.Add(backoff(r.Attempts))
+		log.Printf("Campaign %s: send to %s failed (attempt %d), retrying at %s: %v",
+			c.ID, r.Phone, r.Attempts, r.NextAttemptAt.Format(time.RFC3339), err)
+	case err != nil:
+		if whatsapp.IsRetryable(err) {
+			r.Attempts++
+		}
+		r.Status = models.RecipientFailed
+		r.Error = err.Error()
+		r.NextAttemptAt = time.Time{}
+	default:
+		r.Status = models.RecipientSent
+		r.MessageID = messageID
+		r.Error = ""
+		r.NextAttemptAt = time.Time{}
+		r.SentAt = now
+		m.messages[messageID] = ref
+		m.sentTimes = append(m.sentTimes, now)
+	}
+	updateCounts(c)
+	m.markDirty(c.ID, ref.index)
+}
+
+// backoff returns the wait before the given retry attempt
+func backoff(attempt int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// markDirty records that a campaign changed, along with the indexes of any changed recipients
+func (m *Manager) markDirty(id string, indexes ...int) {
+	if m.dirty[id] == nil {
+		m.dirty[id] = make(map[int]bool)
+	}
+	for _, i := range indexes {
+		m.dirty[id][i] = true
+	}
+}
+
+// update applies a change to a campaign and saves it
+func (m *Manager) update(ctx context.Context, id string, change func(c *models.Campaign) error) (*models.Campaign, error) {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	c, ok := m.campaigns[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if err := change(c); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+	c.UpdatedAt = time.Now()
+	updateCounts(c)
+	result := copyCampaign(c)
+	m.mu.Unlock()
+
+	return result, m.store.Save(ctx, result)
+}
+
+// saveDirty stores the changes since the last save: the campaign's status and counts
+// with only the recipients that changed, or the whole campaign once it has finished
+func (m *Manager) saveDirty(ctx context.Context) {
+	type change struct {
+		campaign   *models.Campaign
+		recipients map[int]models.Recipient
+		full       bool
+	}
+
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	var changes []change
+	for id, indexes := range m.dirty {
+		c := m.campaigns[id]
+		if m.compact[id] {
+			changes = append(changes, change{campaign: copyCampaign(c), full: true})
+			continue
+		}
+		recipients := make(map[int]models.Recipient, len(indexes))
+		for i := range indexes {
+			r := c.Recipients[i]
+			r.Variables = append([]string(nil), r.Variables...)
+			recipients[i] = r
+		}
+		changes = append(changes, change{campaign: summarize(c), recipients: recipients})
+	}
+	m.dirty = make(map[string]map[int]bool)
+	m.compact = make(map[string]bool)
+	m.mu.Unlock()
+
+	for _, ch := range changes {
+		var err error
+		if ch.full {
+			err = m.store.Save(ctx, ch.campaign)
+		} else {
+			err = m.store.SaveRecipients(ctx, ch.campaign, ch.recipients)
+		}
+		if err != nil {
+			log.Printf("Failed to save campaign %s: %v", ch.campaign.ID, err)
+			m.retrySave(ch.campaign.ID, ch.recipients, ch.full)
+		}
+	}
+}
+
+// retrySave marks the changes of a failed save dirty again so the next tick stores them
+func (m *Manager) retrySave(id string, recipients map[int]models.Recipient, full bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.markDirty(id)
+	for i := range recipients {
+		m.dirty[id][i] = true
+	}
+	if full {
+		m.compact[id] = true
+	}
+}
+
+// addRecipients appends new recipients with normalized phone numbers, skipping duplicates
+func addRecipients(c *models.Campaign, recipients []models.Recipient) {
+	seen := make(map[string]bool, len(c.Recipients))
+	for _, r := range c.Recipients {
+		seen[r.Phone] = true
+	}
+
+	now := time.Now()
+	for _, r := range recipients {
+		phone := whatsapp.NormalizePhone(r.Phone)
+		if phone == "" || seen[phone] {
+			continue
+		}
+		seen[phone] = true
+		c.Recipients = append(c.Recipients, models.Recipient{
+			Phone:     phone,
+			Variables: append([]string(nil), r.Variables...),
+			Status:    models.RecipientPending,
+			UpdatedAt: now,
+		})
+	}
+}
+
+// updateCounts recounts the recipients in each status
+func updateCounts(c *models.Campaign) {
+	c.Counts = make(map[string]int)
+	for _, r := range c.Recipients {
+		c.Counts[r.Status]++
+	}
+}
+
+// newID returns a random campaign ID
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,148 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/consent"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// newTestCampaign creates a manager with a running campaign for one recipient
+func newTestCampaign(t *testing.T, store Store) (*Manager, *models.Campaign) {
+	t.Helper()
+
+	ctx := context.Background()
+	m, err := NewManager(ctx, &config.Config{CampaignRatePerSecond: 10}, store, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	c, err := m.Create(ctx, &models.CampaignRequest{
+		Name:       "promo",
+		Template:   models.OutboundTemplate{Name: "promo", Language: "en_US"},
+		Recipients: []models.Recipient{{Phone: "+62 812-3456-7890"}},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if c, err = m.Schedule(ctx, c.ID, time.Time{}); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	return m, c
+}
+
+func TestRecordSendRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		attempts     int
+		err          error
+		wantStatus   string
+		wantAttempts int
+		wantRetry    bool
+	}{
+		{name: "sent", wantStatus: models.RecipientSent},
+		{name: "rate limited", err: &whatsapp.APIError{StatusCode: 429}, wantStatus: models.RecipientPending, wantAttempts: 1, wantRetry: true},
+		{name: "server error", err: &whatsapp.APIError{StatusCode: 503}, wantStatus: models.RecipientPending, wantAttempts: 1, wantRetry: true},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, wantStatus: models.RecipientPending, wantAttempts: 1, wantRetry: true},
+		{name: "timeout", err: context.DeadlineExceeded, wantStatus: models.RecipientPending, wantAttempts: 1, wantRetry: true},
+		{name: "rejected", err: &whatsapp.APIError{StatusCode: 400}, wantStatus: models.RecipientFailed},
+		{name: "opted out", err: consent.ErrOptedOut, wantStatus: models.RecipientSkipped},
+		{name: "retry limit reached", attempts: maxSendAttempts - 1, err: &whatsapp.APIError{StatusCode: 429}, wantStatus: models.RecipientFailed, wantAttempts: maxSendAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, c := newTestCampaign(t, NewMemoryStore())
+			m.campaigns[c.ID].Recipients[0].Attempts = tt.attempts
+
+			m.recordSend(recipientRef{campaignID: c.ID}, "wamid.1", tt.err)
+
+			r := m.campaigns[c.ID].Recipients[0]
+			if r.Status != tt.wantStatus || r.Attempts != tt.wantAttempts {
+				t.Errorf("recipient = %s after %d attempts, want %s after %d", r.Status, r.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if got := !r.NextAttemptAt.IsZero(); got != tt.wantRetry {
+				t.Errorf("retry scheduled = %v, want %v", got, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestNextBatchWaitsForRetry(t *testing.T) {
+	m, c := newTestCampaign(t, NewMemoryStore())
+	now := time.Now()
+
+	m.recordSend(recipientRef{campaignID: c.ID}, "", &whatsapp.APIError{StatusCode: 429})
+	if batch := m.nextBatch(now); len(batch) != 0 {
+		t.Fatalf("nextBatch() before the retry time = %d messages, want 0", len(batch))
+	}
+	if status := m.campaigns[c.ID].Status; status != models.CampaignRunning {
+		t.Fatalf("campaign status = %s, want %s while a retry is pending", status, models.CampaignRunning)
+	}
+	if batch := m.nextBatch(now.Add(retryDelay + time.Second)); len(batch) != 1 {
+		t.Errorf("nextBatch() after the retry time = %d messages, want 1", len(batch))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: retryDelay},
+		{attempt: 2, want: 2 * retryDelay},
+		{attempt: 3, want: 4 * retryDelay},
+		{attempt: 20, want: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestFileStoreSavesChangedRecipients(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	m, c := newTestCampaign(t, store)
+
+	m.nextBatch(time.Now())
+	m.recordSend(recipientRef{campaignID: c.ID}, "wamid.1", nil)
+	m.saveDirty(ctx)
+	if _, err := os.Stat(store.logPath(c.ID)); err != nil {
+		t.Fatalf("progress log not written: %v", err)
+	}
+
+	campaigns, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(campaigns) != 1 {
+		t.Fatalf("List() = %d campaigns, want 1", len(campaigns))
+	}
+	got := campaigns[0]
+	if r := got.Recipients[0]; r.Status != models.RecipientSent || r.MessageID != "wamid.1" {
+		t.Errorf("stored recipient = %s %q, want sent wamid.1", r.Status, r.MessageID)
+	}
+	if got.Status != models.CampaignRunning || got.Counts[models.RecipientSent] != 1 {
+		t.Errorf("stored campaign = %s %v, want running with 1 sent", got.Status, got.Counts)
+	}
+
+	// A full save folds the log into the campaign file
+	if _, err := m.Pause(ctx, c.ID); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if _, err := os.Stat(store.logPath(c.ID)); !os.IsNotExist(err) {
+		t.Errorf("progress log still present after a full save: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package campaign
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// ParseRecipientsCSV reads recipients from CSV with a header row. The first column is the
+// phone number and the remaining columns are the template parameters, in order.
+func ParseRecipientsCSV(r io.Reader) ([]models.Recipient, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Skip the header row
+	if _, err := reader.Read(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("recipient list is empty")
+		}
+		return nil, fmt.Errorf("failed to read recipient list: %w", err)
+	}
+
+	var recipients []models.Recipient
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recipient list: %w", err)
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			return nil, fmt.Errorf("line %d: phone number is required", line)
+		}
+		recipients = append(recipients, models.Recipient{
+			Phone:     record[0],
+			Variables: record[1:],
+		})
+	}
+	return recipients, nil
+}
@@ -0,0 +1,233 @@
+package campaign
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Store persists campaigns with their recipients
+type Store interface {
+	// Save stores the campaign
+	Save(ctx context.Context, c *models.Campaign) error
+	// SaveRecipients stores the campaign's status and counts with only the given recipients, keyed by index
+	SaveRecipients(ctx context.Context, c *models.Campaign, recipients map[int]models.Recipient) error
+	// List returns every stored campaign
+	List(ctx context.Context) ([]*models.Campaign, error)
+}
+
+// NewStore returns a file store in CAMPAIGN_STORE_DIR, or an in-memory store when it is unset
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.CampaignStoreDir == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewFileStore(cfg.CampaignStoreDir)
+}
+
+// MemoryStore keeps campaigns in process memory
+type MemoryStore struct {
+	mu        sync.RWMutex
+	campaigns map[string]*models.Campaign
+}
+
+// NewMemoryStore creates a new in-memory campaign store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		campaigns: make(map[string]*models.Campaign),
+	}
+}
+
+// Save stores a copy of the campaign
+func (s *MemoryStore) Save(ctx context.Context, c *models.Campaign) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.campaigns[c.ID] = copyCampaign(c)
+	return nil
+}
+
+// SaveRecipients updates the stored campaign with the changed recipients
+func (s *MemoryStore) SaveRecipients(ctx context.Context, c *models.Campaign, recipients map[int]models.Recipient) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.campaigns[c.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	s.campaigns[c.ID] = applyProgress(stored, progressEntry{Campaign: c, Recipients: recipients})
+	return nil
+}
+
+// List returns copies of every stored campaign
+func (s *MemoryStore) List(ctx context.Context) ([]*models.Campaign, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	campaigns := make([]*models.Campaign, 0, len(s.campaigns))
+	for _, c := range s.campaigns {
+		campaigns = append(campaigns, copyCampaign(c))
+	}
+	return campaigns, nil
+}
+
+// progressEntry is a recipient update appended to a campaign's progress log
+type progressEntry struct {
+	// Campaign holds the campaign fields without its recipients
+	Campaign   *models.Campaign         `json:"campaign"`
+	Recipients map[int]models.Recipient `json:"recipients"`
+}
+
+// FileStore keeps each campaign as a JSON file in a directory.
+// Recipient updates are appended to a progress log next to it until the next full save.
+type FileStore struct {
+	// mu keeps a full save and its log removal apart from log appends
+	mu  sync.Mutex
+	dir *jsonfile.Dir
+}
+
+// NewFileStore creates a new file-backed campaign store
+func NewFileStore(dir string) (*FileStore, error) {
+	d, err := jsonfile.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create campaign store directory: %w", err)
+	}
+	return &FileStore{dir: d}, nil
+}
+
+// Save writes the campaign file atomically
+func (s *FileStore) Save(ctx context.Context, c *models.Campaign) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.dir.Put(c.ID, c); err != nil {
+		return fmt.Errorf("failed to write campaign: %w", err)
+	}
+
+	// The file now holds every recipient, so the progress log is no longer needed
+	if err := os.Remove(s.logPath(c.ID)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove campaign log: %w", err)
+	}
+	return nil
+}
+
+// SaveRecipients appends the changed recipients to the campaign's progress log
+func (s *FileStore) SaveRecipients(ctx context.Context, c *models.Campaign, recipients map[int]models.Recipient) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(progressEntry{Campaign: summarize(c), Recipients: recipients})
+	if err != nil {
+		return fmt.Errorf("failed to encode campaign progress: %w", err)
+	}
+
+	f, err := os.OpenFile(s.logPath(c.ID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open campaign log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write campaign log: %w", err)
+	}
+	return nil
+}
+
+// List reads every campaign file in the directory
+func (s *FileStore) List(ctx context.Context) ([]*models.Campaign, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := jsonfile.List[models.Campaign](s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list campaigns: %w", err)
+	}
+
+	campaigns := make([]*models.Campaign, 0, len(stored))
+	for _, c := range stored {
+		campaign, err := s.replayLog(c)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, campaign)
+	}
+	return campaigns, nil
+}
+
+// replayLog applies the campaign's progress log to the state read from its file
+func (s *FileStore) replayLog(c *models.Campaign) (*models.Campaign, error) {
+	f, err := os.Open(s.logPath(c.ID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read campaign log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry progressEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash can leave the last line partly written
+			log.Printf("Skipping unreadable progress of campaign %s: %v", c.ID, err)
+			continue
+		}
+		c = applyProgress(c, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read campaign log: %w", err)
+	}
+	return c, nil
+}
+
+// logPath returns the path of a campaign's progress log
+func (s *FileStore) logPath(id string) string {
+	return strings.TrimSuffix(s.dir.Path(id), ".json") + ".log"
+}
+
+// applyProgress returns a copy of the campaign with a progress entry applied
+func applyProgress(c *models.Campaign, entry progressEntry) *models.Campaign {
+	updated := copyCampaign(entry.Campaign)
+	updated.Recipients = copyCampaign(c).Recipients
+	for i, r := range entry.Recipients {
+		if i >= 0 && i < len(updated.Recipients) {
+			r.Variables = append([]string(nil), r.Variables...)
+			updated.Recipients[i] = r
+		}
+	}
+	return updated
+}
+
+// copyCampaign returns a deep copy so callers cannot mutate stored state
+func copyCampaign(c *models.Campaign) *models.Campaign {
+	cp := *c
+	cp.Recipients = make([]models.Recipient, len(c.Recipients))
+	for i, r := range c.Recipients {
+		r.Variables = append([]string(nil), r.Variables...)
+		cp.Recipients[i] = r
+	}
+	cp.Counts = make(map[string]int, len(c.Counts))
+	for status, n := range c.Counts {
+		cp.Counts[status] = n
+	}
+	return &cp
+}
+
+// summarize returns a copy of the campaign without its recipients
+func summarize(c *models.Campaign) *models.Campaign {
+	summary := *c
+	summary.Recipients = nil
+	return copyCampaign(&summary)
+}
@@ -0,0 +1,172 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Prefix marks a message as a command
+const Prefix = "/"
+
+// Request describes the message that invoked a command
+type Request struct {
+	Message models.Message
+	Session *models.Session
+	// Language is the language replies should be written in
+	Language string
+	// Name is the command name or keyword the user sent
+	Name string
+	// Args are the words following the command name
+	Args []string
+}
+
+// Handler runs a command and returns the reply sent to the user.
+// Changes to the request's session are saved after the handler returns.
+type Handler func(ctx context.Context, req *Request) (string, error)
+
+// Command is a user command such as /help
+type Command struct {
+	// Name is invoked as /name
+	Name string
+	// Aliases are alternative names, also invoked with the prefix
+	Aliases []string
+	// Keywords trigger the command when they are the whole message, without the prefix,
+	// e.g. localized words like "bantuan"
+	Keywords []string
+	// Description is shown by /help; it may be an i18n message key
+	Description string
+	Handler     Handler
+}
+
+// Dispatcher routes command messages to their handlers
+type Dispatcher struct {
+	defaultLanguage string
+
+	mu       sync.RWMutex
+	commands []*Command
+	names    map[string]*Command
+	keywords map[string]*Command
+}
+
+// NewDispatcher creates an empty dispatcher replying in defaultLanguage to users without a preference
+func NewDispatcher(defaultLanguage string) *Dispatcher {
+	return &Dispatcher{
+		defaultLanguage: defaultLanguage,
+		names:           make(map[string]*Command),
+		keywords:        make(map[string]*Command),
+	}
+}
+
+// Register adds a command; names, aliases and keywords must not already be taken
+func (d *Dispatcher) Register(cmd Command) error {
+	if cmd.Name == "" {
+		return fmt.Errorf("command name is required")
+	}
+	if cmd.Handler == nil {
+		return fmt.Errorf("command %s has no handler", cmd.Name)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Check every name before registering any of them
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, name := range names {
+		if _, ok := d.names[normalize(name)]; ok {
+			return fmt.Errorf("command %s is already registered", name)
+		}
+	}
+	for _, keyword := range cmd.Keywords {
+		if _, ok := d.keywords[normalize(keyword)]; ok {
+			return fmt.Errorf("command keyword %q is already registered", keyword)
+		}
+	}
+
+	c := cmd
+	d.commands = append(d.commands, &c)
+	for _, name := range names {
+		d.names[normalize(name)] = &c
+	}
+	for _, keyword := range cmd.Keywords {
+		d.keywords[normalize(keyword)] = &c
+	}
+	return nil
+}
+
+// Commands returns the registered commands in registration order
+func (d *Dispatcher) Commands() []Command {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	commands := make([]Command, len(d.commands))
+	for i, cmd := range d.commands {
+		commands[i] = *cmd
+	}
+	return commands
+}
+
+// Lookup finds the command invoked by a message.
+// ok is false when the message is not a command; cmd is nil for an unknown prefixed command.
+func (d *Dispatcher) Lookup(text string) (cmd *Command, name string, args []string, ok bool) {
+	text = strings.TrimSpace(text)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	// Prefixed commands take arguments
+	if strings.HasPrefix(text, Prefix) {
+		fields := strings.Fields(strings.TrimPrefix(text, Prefix))
+		if len(fields) == 0 {
+			return nil, "", nil, true
+		}
+		name = normalize(fields[0])
+		return d.names[name], name, fields[1:], true
+	}
+
+	// Keywords must be the whole message
+	if cmd, ok := d.keywords[normalize(text)]; ok {
+		return cmd, normalize(text), nil, true
+	}
+	return nil, "", nil, false
+}
+
+// Dispatch runs the command invoked by a message and returns its reply.
+// handled is false when the message is not a command and should be processed normally.
+func (d *Dispatcher) Dispatch(ctx context.Context, message models.Message, sess *models.Session) (reply string, handled bool, err error) {
+	cmd, name, args, ok := d.Lookup(message.Text)
+	if !ok {
+		return "", false, nil
+	}
+	lang := sess.Language
+	if lang == "" {
+		lang = sess.DetectedLanguage
+	}
+	if lang == "" {
+		lang = d.defaultLanguage
+	}
+	if cmd == nil {
+		return i18n.T(lang, "command.unknown", Prefix+name), true, nil
+	}
+
+	reply, err = cmd.Handler(ctx, &Request{
+		Message:  message,
+		Session:  sess,
+		Language: lang,
+		Name:     name,
+		Args:     args,
+	})
+	if err != nil {
+		return i18n.T(lang, "command.failed"), true, fmt.Errorf("command %s failed: %w", cmd.Name, err)
+	}
+	return reply, true, nil
+}
+
+// normalize lowercases and collapses whitespace so lookups ignore case and spacing
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all configuration for the application
@@ -10,38 +11,323 @@ type Config struct {
 	// Server Configuration
 	Port string
 
+	// Admin API Configuration
+	AdminAPIToken string
+
+	// LLM Service Configuration
+	LLMServiceToken string
+
+	// Outbound API Configuration
+	OutboundAPIToken         string
+	OutboundFallbackTemplate string
+	OutboundFallbackLanguage string
+	IdempotencyStoreDir      string
+
+	// Campaign Configuration
+	CampaignStoreDir      string
+	CampaignRatePerSecond int
+	CampaignDailyLimit    int
+
+	// Consent Configuration
+	ConsentStoreDir      string
+	ConsentStopKeywords  []string
+	ConsentStartKeywords []string
+
+	// Scheduler Configuration
+	SchedulerStoreDir  string
+	SchedulerRetention int
+
+	// Agent Handoff Configuration
+	AgentAPIToken        string
+	HandoffTimeout       int
+	HandoffAfterFailures int
+	EventBufferSize      int
+
+	// Business Hours Configuration
+	BusinessHours         string
+	BusinessHolidays      []string
+	BusinessTimezone      string
+	BusinessClosedMessage string
+
 	// WhatsApp Configuration
-	WhatsAppAPIURL  string
-	WhatsAppToken   string
-	WhatsAppPhoneID string
+	WhatsAppAPIURL     string
+	WhatsAppToken      string
+	WhatsAppPhoneID    string
+	WhatsAppMediaMaxMB int
+
+	// Transcription Configuration
+	TranscribeProvider string
+	TranscribeBaseURL  string
+	TranscribeAPIKey   string
+	TranscribeModel    string
+	TranscribeCommand  string
+	TranscribeLanguage string
+	TranscribeTimeout  int
+	TranscribeEcho     bool
+
+	// Speech Synthesis Configuration
+	TTSProvider  string
+	TTSBaseURL   string
+	TTSAPIKey    string
+	TTSModel     string
+	TTSVoice     string
+	TTSCommand   string
+	TTSTimeout   int
+	TTSMaxChars  int
+	TTSReplyMode string
+
+	// Shared Document Configuration
+	DocumentMaxChars    int
+	DocumentChunkSize   int
+	DocumentMaxPerUser  int
+	DocumentTTL         int
+	DocumentTokenBudget int
+
+	// LLM Provider Configuration
+	LLMProvider       string
+	LLMFallbackModels []string
+	LLMVisionModels   []string
+
+	// Generation Defaults
+	Temperature   float64
+	MaxTokens     int
+	TopP          float64
+	StopSequences []string
+	Seed          int
+
+	// Tool Calling Configuration
+	ToolMaxIterations  int
+	WebhookToolsFile   string
+	WebhookToolTimeout int
 
 	// OpenRouter Configuration
 	OpenRouterAPIKey    string
 	OpenRouterModelName string
 
+	// OpenAI-compatible Configuration
+	OpenAIAPIKey    string
+	OpenAIBaseURL   string
+	OpenAIModelName string
+
+	// Ollama Configuration
+	OllamaServerURL string
+	OllamaModelName string
+
 	// Redis Configuration (for message passing)
 	RedisURL      string
 	RedisPassword string
+
+	// Embedding Configuration
+	EmbeddingProvider string
+	EmbeddingModel    string
+
+	// Knowledge Base Configuration
+	KnowledgeDir          string
+	KnowledgeIndexPath    string
+	KnowledgeTopK         int
+	KnowledgeMinScore     float64
+	KnowledgeChunkSize    int
+	KnowledgeChunkOverlap int
+	KnowledgeTimeout      int
+
+	// Session Configuration
+	SessionStoreDir   string
+	DefaultLanguage   string
+	DefaultTimezone   string
+	LanguageDetection bool
+	CommandKeywords   bool
+
+	// Intent Router Configuration
+	IntentRulesFile      string
+	IntentReloadInterval int
+
+	// Flow Configuration
+	FlowsFile          string
+	FlowWebhookURL     string
+	FlowWebhookTimeout int
+
+	// Conversation Memory Configuration
+	ConversationStoreDir string
+	MemoryMode           string
+	MemoryTokenBudget    int
+	MemoryRecentTurns    int
+	SummarizerPrompt     string
 }
 
+// LLM providers
+const (
+	ProviderOpenRouter = "openrouter"
+	ProviderOpenAI     = "openai"
+	ProviderOllama     = "ollama"
+)
+
+// DefaultVisionModels are patterns of model names that accept images
+var DefaultVisionModels = []string{
+	"*gpt-4o*", "*gpt-4.1*", "*gpt-4-turbo*", "*claude-3*", "*gemini*",
+	"*vision*", "*llava*", "*pixtral*", "*-vl*", "*llama-4*",
+}
+
+// Memory modes
+const (
+	// MemoryModeTruncate drops the oldest turns once the token budget is exceeded
+	MemoryModeTruncate = "truncate"
+	// MemoryModeSummary folds the oldest turns into a running summary
+	MemoryModeSummary = "summary"
+)
+
+// DefaultSummarizerPrompt is used to condense older conversation turns
+const DefaultSummarizerPrompt = `You maintain the memory of a customer support chat.
+Update the existing summary with the new messages below. Keep every fact the
+assistant may need later, such as names, order numbers, dates, addresses and
+open requests. Reply with the updated summary only.`
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	config := &Config{
 		// Default values or from environment variables
 		Port: getEnv("PORT", "8080"),
 
+		// Admin API Configuration
+		AdminAPIToken: getEnv("ADMIN_API_TOKEN", ""),
+
+		// LLM Service Configuration
+		LLMServiceToken: getEnv("LLM_SERVICE_TOKEN", ""),
+
+		// Outbound API Configuration
+		OutboundAPIToken:         getEnv("OUTBOUND_API_TOKEN", ""),
+		OutboundFallbackTemplate: getEnv("OUTBOUND_FALLBACK_TEMPLATE", ""),
+		OutboundFallbackLanguage: getEnv("OUTBOUND_FALLBACK_LANGUAGE", "en"),
+		IdempotencyStoreDir:      getEnv("IDEMPOTENCY_STORE_DIR", ""),
+
+		// Campaign Configuration
+		CampaignStoreDir:      getEnv("CAMPAIGN_STORE_DIR", ""),
+		CampaignRatePerSecond: getEnvAsInt("CAMPAIGN_RATE_PER_SECOND", 10),
+		CampaignDailyLimit:    getEnvAsInt("CAMPAIGN_DAILY_LIMIT", 1000),
+
+		// Consent Configuration
+		ConsentStoreDir:      getEnv("CONSENT_STORE_DIR", ""),
+		ConsentStopKeywords:  getEnvAsSlice("CONSENT_STOP_KEYWORDS", []string{"STOP", "STOP ALL", "UNSUBSCRIBE", "BERHENTI"}),
+		ConsentStartKeywords: getEnvAsSlice("CONSENT_START_KEYWORDS", []string{"START", "SUBSCRIBE", "MULAI"}),
+
+		// Scheduler Configuration
+		SchedulerStoreDir:  getEnv("SCHEDULER_STORE_DIR", ""),
+		SchedulerRetention: getEnvAsInt("SCHEDULER_RETENTION_HOURS", 168),
+
+		// Agent Handoff Configuration
+		AgentAPIToken:        getEnv("AGENT_API_TOKEN", ""),
+		HandoffTimeout:       getEnvAsInt("HANDOFF_TIMEOUT_MINUTES", 30),
+		HandoffAfterFailures: getEnvAsInt("HANDOFF_AFTER_FAILURES", 2),
+		EventBufferSize:      getEnvAsInt("EVENT_BUFFER_SIZE", 1000),
+
+		// Business Hours Configuration
+		BusinessHours:         getEnv("BUSINESS_HOURS", ""),
+		BusinessHolidays:      getEnvAsSlice("BUSINESS_HOLIDAYS", nil),
+		BusinessTimezone:      getEnv("BUSINESS_TIMEZONE", ""),
+		BusinessClosedMessage: getEnv("BUSINESS_CLOSED_MESSAGE", ""),
+
 		// WhatsApp Configuration
-		WhatsAppAPIURL:  getEnv("WHATSAPP_API_URL", "https://graph.facebook.com/v17.0"),
-		WhatsAppToken:   getEnv("WHATSAPP_TOKEN", ""),
-		WhatsAppPhoneID: getEnv("WHATSAPP_PHONE_ID", ""),
+		WhatsAppAPIURL:     getEnv("WHATSAPP_API_URL", "https://graph.facebook.com/v17.0"),
+		WhatsAppToken:      getEnv("WHATSAPP_TOKEN", ""),
+		WhatsAppPhoneID:    getEnv("WHATSAPP_PHONE_ID", ""),
+		WhatsAppMediaMaxMB: getEnvAsInt("WHATSAPP_MEDIA_MAX_MB", 16),
+
+		// Transcription Configuration
+		TranscribeProvider: getEnv("TRANSCRIBE_PROVIDER", ""),
+		TranscribeBaseURL:  getEnv("TRANSCRIBE_BASE_URL", getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1")),
+		TranscribeAPIKey:   getEnv("TRANSCRIBE_API_KEY", getEnv("OPENAI_API_KEY", "")),
+		TranscribeModel:    getEnv("TRANSCRIBE_MODEL", "whisper-1"),
+		TranscribeCommand:  getEnv("TRANSCRIBE_COMMAND", ""),
+		TranscribeLanguage: getEnv("TRANSCRIBE_LANGUAGE", ""),
+		TranscribeTimeout:  getEnvAsInt("TRANSCRIBE_TIMEOUT_SECONDS", 60),
+		TranscribeEcho:     getEnvAsBool("TRANSCRIBE_ECHO", false),
+
+		// Speech Synthesis Configuration
+		TTSProvider:  getEnv("TTS_PROVIDER", ""),
+		TTSBaseURL:   getEnv("TTS_BASE_URL", getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1")),
+		TTSAPIKey:    getEnv("TTS_API_KEY", getEnv("OPENAI_API_KEY", "")),
+		TTSModel:     getEnv("TTS_MODEL", "tts-1"),
+		TTSVoice:     getEnv("TTS_VOICE", "alloy"),
+		TTSCommand:   getEnv("TTS_COMMAND", ""),
+		TTSTimeout:   getEnvAsInt("TTS_TIMEOUT_SECONDS", 60),
+		TTSMaxChars:  getEnvAsInt("TTS_MAX_CHARS", 1000),
+		TTSReplyMode: getEnv("TTS_REPLY_MODE", "text"),
+
+		// Shared Document Configuration
+		DocumentMaxChars:    getEnvAsInt("DOCUMENT_MAX_CHARS", 100000),
+		DocumentChunkSize:   getEnvAsInt("DOCUMENT_CHUNK_SIZE", 1500),
+		DocumentMaxPerUser:  getEnvAsInt("DOCUMENT_MAX_PER_USER", 3),
+		DocumentTTL:         getEnvAsInt("DOCUMENT_TTL_HOURS", 24),
+		DocumentTokenBudget: getEnvAsInt("DOCUMENT_TOKEN_BUDGET", 3000),
+
+		// LLM Provider Configuration
+		LLMProvider:       getEnv("LLM_PROVIDER", ProviderOpenRouter),
+		LLMFallbackModels: getEnvAsSlice("LLM_FALLBACK_MODELS", nil),
+		LLMVisionModels:   getEnvAsSlice("LLM_VISION_MODELS", DefaultVisionModels),
+
+		// Generation Defaults
+		Temperature:   getEnvAsFloat("LLM_TEMPERATURE", 0.7),
+		MaxTokens:     getEnvAsInt("LLM_MAX_TOKENS", 0),
+		TopP:          getEnvAsFloat("LLM_TOP_P", 0),
+		StopSequences: getEnvAsSlice("LLM_STOP", nil),
+		Seed:          getEnvAsInt("LLM_SEED", 0),
+
+		// Tool Calling Configuration
+		ToolMaxIterations:  getEnvAsInt("LLM_TOOL_MAX_ITERATIONS", 5),
+		WebhookToolsFile:   getEnv("WEBHOOK_TOOLS_FILE", ""),
+		WebhookToolTimeout: getEnvAsInt("WEBHOOK_TOOL_TIMEOUT_SECONDS", 10),
 
 		// OpenRouter Configuration
 		OpenRouterAPIKey:    getEnv("OPENROUTER_API_KEY", ""),
 		OpenRouterModelName: getEnv("OPENROUTER_MODEL", "meta-llama/llama-3-70b-instruct"),
 
+		// OpenAI-compatible Configuration
+		OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
+		OpenAIBaseURL:   getEnv("OPENAI_BASE_URL", ""),
+		OpenAIModelName: getEnv("OPENAI_MODEL", "gpt-4o-mini"),
+
+		// Ollama Configuration
+		OllamaServerURL: getEnv("OLLAMA_SERVER_URL", "http://localhost:11434"),
+		OllamaModelName: getEnv("OLLAMA_MODEL", "llama3"),
+
 		// Redis Configuration
 		RedisURL:      getEnv("REDIS_URL", "localhost:6379"),
 		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+
+		// Embedding Configuration
+		EmbeddingProvider: getEnv("EMBEDDING_PROVIDER", ProviderOpenAI),
+		EmbeddingModel:    getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+
+		// Knowledge Base Configuration
+		KnowledgeDir:          getEnv("KNOWLEDGE_DIR", ""),
+		KnowledgeIndexPath:    getEnv("KNOWLEDGE_INDEX_PATH", ""),
+		KnowledgeTopK:         getEnvAsInt("KNOWLEDGE_TOP_K", 4),
+		KnowledgeMinScore:     getEnvAsFloat("KNOWLEDGE_MIN_SCORE", 0.3),
+		KnowledgeChunkSize:    getEnvAsInt("KNOWLEDGE_CHUNK_SIZE", 1000),
+		KnowledgeChunkOverlap: getEnvAsInt("KNOWLEDGE_CHUNK_OVERLAP", 150),
+		KnowledgeTimeout:      getEnvAsInt("KNOWLEDGE_TIMEOUT_SECONDS", 600),
+
+		// Session Configuration
+		SessionStoreDir:   getEnv("SESSION_STORE_DIR", ""),
+		DefaultLanguage:   getEnv("DEFAULT_LANGUAGE", "en"),
+		DefaultTimezone:   getEnv("DEFAULT_TIMEZONE", "UTC"),
+		LanguageDetection: getEnvAsBool("LANGUAGE_DETECTION", true),
+		CommandKeywords:   getEnvAsBool("COMMAND_KEYWORDS", false),
+
+		// Intent Router Configuration
+		IntentRulesFile:      getEnv("INTENT_RULES_FILE", ""),
+		IntentReloadInterval: getEnvAsInt("INTENT_RELOAD_SECONDS", 10),
+
+		// Flow Configuration
+		FlowsFile:          getEnv("FLOWS_FILE", ""),
+		FlowWebhookURL:     getEnv("FLOW_WEBHOOK_URL", ""),
+		FlowWebhookTimeout: getEnvAsInt("FLOW_WEBHOOK_TIMEOUT_SECONDS", 10),
+
+		// Conversation Memory Configuration
+		ConversationStoreDir: getEnv("CONVERSATION_STORE_DIR", ""),
+		MemoryMode:           getEnv("MEMORY_MODE", MemoryModeTruncate),
+		MemoryTokenBudget:    getEnvAsInt("MEMORY_TOKEN_BUDGET", 2000),
+		MemoryRecentTurns:    getEnvAsInt("MEMORY_RECENT_TURNS", 6),
+		SummarizerPrompt:     getEnv("MEMORY_SUMMARIZER_PROMPT", DefaultSummarizerPrompt),
 	}
 
 	return config
@@ -56,6 +342,40 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// Helper function to get a comma-separated environment variable as a slice
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Helper function to get environment variable as float
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// Helper function to get environment variable as boolean
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // Helper function to get environment variable as integer
 func getEnvAsInt(key string, defaultValue int) int {
 	valueStr := getEnv(key, "")
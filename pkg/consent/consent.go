@@ -0,0 +1,176 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/keylock"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// ErrOptedOut is returned when sending to a number that opted out
+var ErrOptedOut = errors.New("recipient has opted out of messages")
+
+// ErrInvalid is returned for consent changes that can't be applied
+var ErrInvalid = errors.New("invalid consent")
+
+// Consent sources set by the service itself
+const (
+	SourceKeyword = "keyword"
+	SourceAPI     = "api"
+)
+
+// Keyword actions
+const (
+	KeywordNone = iota
+	KeywordStop
+	KeywordStart
+)
+
+// Manager records consent and decides whether numbers may be messaged
+type Manager struct {
+	store Store
+	// locks serializes changes to each number's record
+	locks         *keylock.Locks
+	stopKeywords  map[string]bool
+	startKeywords map[string]bool
+	stopKeyword   string
+	startKeyword  string
+}
+
+// NewManager creates a consent manager with the configured stop and start keywords
+func NewManager(cfg *config.Config, store Store) *Manager {
+	return &Manager{
+		store:         store,
+		locks:         keylock.New(),
+		stopKeywords:  keywordSet(cfg.ConsentStopKeywords),
+		startKeywords: keywordSet(cfg.ConsentStartKeywords),
+		stopKeyword:   firstKeyword(cfg.ConsentStopKeywords),
+		startKeyword:  firstKeyword(cfg.ConsentStartKeywords),
+	}
+}
+
+// StopKeyword returns the keyword users are told to send to opt out
+func (m *Manager) StopKeyword() string {
+	return m.stopKeyword
+}
+
+// StartKeyword returns the keyword users are told to send to opt back in
+func (m *Manager) StartKeyword() string {
+	return m.startKeyword
+}
+
+// Keyword reports whether a message is a stop or start keyword
+func (m *Manager) Keyword(text string) int {
+	key := normalizeKeyword(text)
+	switch {
+	case m.stopKeywords[key]:
+		return KeywordStop
+	case m.startKeywords[key]:
+		return KeywordStart
+	default:
+		return KeywordNone
+	}
+}
+
+// Get returns the consent record of a phone number, or nil if there is none
+func (m *Manager) Get(ctx context.Context, phone string) (*models.Consent, error) {
+	return m.store.Get(ctx, whatsapp.NormalizePhone(phone))
+}
+
+// List returns every consent record
+func (m *Manager) List(ctx context.Context) ([]*models.Consent, error) {
+	return m.store.List(ctx)
+}
+
+// OptedOut reports whether a phone number has opted out
+func (m *Manager) OptedOut(ctx context.Context, phone string) (bool, error) {
+	consent, err := m.Get(ctx, phone)
+	if err != nil {
+		return false, err
+	}
+	return consent != nil && consent.Status == models.ConsentOptedOut, nil
+}
+
+// Set records a phone number opting in or out. Changes to the same number are applied one
+// at a time, so concurrent changes don't drop each other's timestamps.
+func (m *Manager) Set(ctx context.Context, phone, status, source string) (*models.Consent, error) {
+	phone = whatsapp.NormalizePhone(phone)
+	if phone == "" {
+		return nil, fmt.Errorf("%w: phone number is required", ErrInvalid)
+	}
+	if status != models.ConsentOptedIn && status != models.ConsentOptedOut {
+		return nil, fmt.Errorf("%w: status must be %s or %s", ErrInvalid, models.ConsentOptedIn, models.ConsentOptedOut)
+	}
+
+	unlock := m.locks.Lock(phone)
+	defer unlock()
+
+	consent, err := m.store.Get(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	if consent == nil {
+		consent = &models.Consent{Phone: phone}
+	}
+
+	now := time.Now()
+	consent.Status = status
+	consent.Source = source
+	consent.UpdatedAt = now
+	if status == models.ConsentOptedIn {
+		consent.OptedInAt = now
+	} else {
+		consent.OptedOutAt = now
+	}
+
+	if err := m.store.Save(ctx, consent); err != nil {
+		return nil, err
+	}
+	log.Printf("%s is now %s (%s)", phone, status, source)
+	return consent, nil
+}
+
+// Allowed refuses messages to numbers that opted out. It implements whatsapp.Guard;
+// if the consent store can't be read, the message is refused.
+func (m *Manager) Allowed(to string) error {
+	optedOut, err := m.OptedOut(context.Background(), to)
+	if err != nil {
+		return fmt.Errorf("failed to check consent: %w", err)
+	}
+	if optedOut {
+		return ErrOptedOut
+	}
+	return nil
+}
+
+// keywordSet builds a lookup set of normalized keywords
+func keywordSet(keywords []string) map[string]bool {
+	set := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		if key := normalizeKeyword(keyword); key != "" {
+			set[key] = true
+		}
+	}
+	return set
+}
+
+// firstKeyword returns the first keyword in the list, or an empty string
+func firstKeyword(keywords []string) string {
+	if len(keywords) == 0 {
+		return ""
+	}
+	return keywords[0]
+}
+
+// normalizeKeyword lowercases and trims a message so keywords match regardless of case and punctuation
+func normalizeKeyword(text string) string {
+	text = strings.Trim(strings.TrimSpace(text), ".!")
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
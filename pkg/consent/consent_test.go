@@ -0,0 +1,230 @@
+package consent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/whatsapp"
+)
+
+// testConfig uses the default stop and start keywords
+func testConfig() *config.Config {
+	return &config.Config{
+		ConsentStopKeywords:  []string{"STOP", "STOP ALL", "UNSUBSCRIBE", "BERHENTI"},
+		ConsentStartKeywords: []string{"START", "SUBSCRIBE", "MULAI"},
+	}
+}
+
+func TestKeyword(t *testing.T) {
+	m := NewManager(testConfig(), NewMemoryStore())
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		// Stop keywords, in any case and with surrounding spaces or punctuation
+		{text: "STOP", want: KeywordStop},
+		{text: "stop", want: KeywordStop},
+		{text: "  Stop!  ", want: KeywordStop},
+		{text: "stop   all", want: KeywordStop},
+		{text: "Berhenti.", want: KeywordStop},
+		{text: "unsubscribe", want: KeywordStop},
+
+		// Start keywords
+		{text: "START", want: KeywordStart},
+		{text: "mulai", want: KeywordStart},
+		{text: "Subscribe!", want: KeywordStart},
+
+		// Only a message consisting of the keyword counts
+		{text: "please stop", want: KeywordNone},
+		{text: "stop sending promos", want: KeywordNone},
+		{text: "stopping", want: KeywordNone},
+		{text: "start?", want: KeywordNone},
+		{text: "", want: KeywordNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := m.Keyword(tt.text); got != tt.want {
+				t.Errorf("Keyword(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeywordsAreConfigurable(t *testing.T) {
+	m := NewManager(&config.Config{
+		ConsentStopKeywords:  []string{"Cancel", " "},
+		ConsentStartKeywords: []string{"Resume"},
+	}, NewMemoryStore())
+
+	if got := m.Keyword("cancel"); got != KeywordStop {
+		t.Errorf("Keyword(cancel) = %d, want %d", got, KeywordStop)
+	}
+	if got := m.Keyword("stop"); got != KeywordNone {
+		t.Errorf("Keyword(stop) = %d, want %d", got, KeywordNone)
+	}
+	if got := m.Keyword(""); got != KeywordNone {
+		t.Errorf("Keyword(\"\") = %d, want %d", got, KeywordNone)
+	}
+	if m.StopKeyword() != "Cancel" || m.StartKeyword() != "Resume" {
+		t.Errorf("StopKeyword(), StartKeyword() = %q, %q, want Cancel, Resume", m.StopKeyword(), m.StartKeyword())
+	}
+}
+
+func TestSetNormalizesPhone(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(testConfig(), NewMemoryStore())
+
+	consent, err := m.Set(ctx, "+62 812-3456-7890", models.ConsentOptedOut, SourceKeyword)
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if consent.Phone != "6281234567890" {
+		t.Errorf("Set() phone = %q, want 6281234567890", consent.Phone)
+	}
+
+	// Every format of the number finds the same record
+	for _, phone := range []string{"6281234567890", "+6281234567890", "62 812 3456 7890", "(62) 812.3456.7890"} {
+		optedOut, err := m.OptedOut(ctx, phone)
+		if err != nil {
+			t.Fatalf("OptedOut(%q) error = %v", phone, err)
+		}
+		if !optedOut {
+			t.Errorf("OptedOut(%q) = false, want true", phone)
+		}
+	}
+
+	if optedOut, _ := m.OptedOut(ctx, "6281234567891"); optedOut {
+		t.Error("OptedOut() = true for another number, want false")
+	}
+}
+
+func TestSetRejectsInvalidChanges(t *testing.T) {
+	m := NewManager(testConfig(), NewMemoryStore())
+
+	tests := []struct {
+		name   string
+		phone  string
+		status string
+	}{
+		{name: "no digits", phone: "+-- ()", status: models.ConsentOptedOut},
+		{name: "empty phone", phone: "", status: models.ConsentOptedIn},
+		{name: "unknown status", phone: "6281234567890", status: "paused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.Set(context.Background(), tt.phone, tt.status, SourceAPI); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Set() error = %v, want %v", err, ErrInvalid)
+			}
+		})
+	}
+}
+
+func TestStartOptsBackIn(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(testConfig(), NewMemoryStore())
+	phone := "6281234567890"
+
+	if _, err := m.Set(ctx, phone, models.ConsentOptedOut, SourceKeyword); err != nil {
+		t.Fatalf("Set(opted_out) error = %v", err)
+	}
+	if err := m.Allowed(phone); !errors.Is(err, ErrOptedOut) {
+		t.Fatalf("Allowed() after STOP error = %v, want %v", err, ErrOptedOut)
+	}
+
+	consent, err := m.Set(ctx, phone, models.ConsentOptedIn, SourceKeyword)
+	if err != nil {
+		t.Fatalf("Set(opted_in) error = %v", err)
+	}
+	if err := m.Allowed(phone); err != nil {
+		t.Errorf("Allowed() after START error = %v, want nil", err)
+	}
+	if consent.OptedOutAt.IsZero() || consent.OptedInAt.Before(consent.OptedOutAt) {
+		t.Errorf("consent timestamps = opted out %v, opted in %v, want both with opt-in last", consent.OptedOutAt, consent.OptedInAt)
+	}
+}
+
+// slowStore widens the gap between reading and saving a record
+type slowStore struct {
+	*MemoryStore
+}
+
+func (s slowStore) Get(ctx context.Context, phone string) (*models.Consent, error) {
+	consent, err := s.MemoryStore.Get(ctx, phone)
+	time.Sleep(10 * time.Millisecond)
+	return consent, err
+}
+
+func TestConcurrentSetsKeepBothChanges(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(testConfig(), slowStore{NewMemoryStore()})
+	phone := "6281234567890"
+
+	var wg sync.WaitGroup
+	for _, status := range []string{models.ConsentOptedOut, models.ConsentOptedIn} {
+		wg.Add(1)
+		go func(status string) {
+			defer wg.Done()
+			if _, err := m.Set(ctx, phone, status, SourceAPI); err != nil {
+				t.Errorf("Set(%s) error = %v", status, err)
+			}
+		}(status)
+	}
+	wg.Wait()
+
+	consent, err := m.Get(ctx, phone)
+	if err != nil || consent == nil {
+		t.Fatalf("Get() = %v, %v, want the record", consent, err)
+	}
+	if consent.OptedOutAt.IsZero() || consent.OptedInAt.IsZero() {
+		t.Errorf("consent timestamps = opted out %v, opted in %v, want both set", consent.OptedOutAt, consent.OptedInAt)
+	}
+}
+
+func TestGuardedSendBlockedAfterStop(t *testing.T) {
+	ctx := context.Background()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"messages": [{"id": "wamid.1"}]}`))
+	}))
+	defer server.Close()
+
+	m := NewManager(testConfig(), NewMemoryStore())
+	client := whatsapp.NewClient(&config.Config{WhatsAppAPIURL: server.URL, WhatsAppPhoneID: "1"})
+	client.SetGuard(m)
+
+	if _, err := client.Send(whatsapp.TextMessage("6281234567890", "hello")); err != nil {
+		t.Fatalf("Send() before STOP error = %v", err)
+	}
+
+	// The user replies STOP from the same number, formatted differently
+	if m.Keyword("Stop") != KeywordStop {
+		t.Fatal("Keyword(Stop) is not a stop keyword")
+	}
+	if _, err := m.Set(ctx, "+62 812 3456 7890", models.ConsentOptedOut, SourceKeyword); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if _, err := client.Send(whatsapp.TextMessage("6281234567890", "promo")); !errors.Is(err, ErrOptedOut) {
+		t.Errorf("Send() after STOP error = %v, want %v", err, ErrOptedOut)
+	}
+	if err := client.SendTemplate("+6281234567890", "promo", "id", nil); !errors.Is(err, ErrOptedOut) {
+		t.Errorf("SendTemplate() after STOP error = %v, want %v", err, ErrOptedOut)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("WhatsApp API received %d requests, want only the one before STOP", got)
+	}
+}
@@ -0,0 +1,120 @@
+package consent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Store persists consent records keyed by phone number
+type Store interface {
+	// Get returns the consent record for a phone number, or nil if there is none
+	Get(ctx context.Context, phone string) (*models.Consent, error)
+	// Save stores the consent record
+	Save(ctx context.Context, consent *models.Consent) error
+	// List returns every consent record
+	List(ctx context.Context) ([]*models.Consent, error)
+}
+
+// NewStore returns a file store in CONSENT_STORE_DIR, or an in-memory store when it is unset
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.ConsentStoreDir == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewFileStore(cfg.ConsentStoreDir)
+}
+
+// MemoryStore keeps consent records in process memory
+type MemoryStore struct {
+	mu       sync.RWMutex
+	consents map[string]models.Consent
+}
+
+// NewMemoryStore creates a new in-memory consent store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		consents: make(map[string]models.Consent),
+	}
+}
+
+// Get returns a copy of the stored consent record
+func (s *MemoryStore) Get(ctx context.Context, phone string) (*models.Consent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	consent, ok := s.consents[phone]
+	if !ok {
+		return nil, nil
+	}
+	return &consent, nil
+}
+
+// Save stores a copy of the consent record
+func (s *MemoryStore) Save(ctx context.Context, consent *models.Consent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.consents[consent.Phone] = *consent
+	return nil
+}
+
+// List returns copies of every consent record
+func (s *MemoryStore) List(ctx context.Context) ([]*models.Consent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	consents := make([]*models.Consent, 0, len(s.consents))
+	for _, consent := range s.consents {
+		c := consent
+		consents = append(consents, &c)
+	}
+	return consents, nil
+}
+
+// FileStore keeps each consent record as a JSON file in a directory
+type FileStore struct {
+	dir *jsonfile.Dir
+}
+
+// NewFileStore creates a new file-backed consent store
+func NewFileStore(dir string) (*FileStore, error) {
+	d, err := jsonfile.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consent store directory: %w", err)
+	}
+	return &FileStore{dir: d}, nil
+}
+
+// Get reads the consent file for a phone number
+func (s *FileStore) Get(ctx context.Context, phone string) (*models.Consent, error) {
+	var consent models.Consent
+	found, err := s.dir.Get(phone, &consent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read consent: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &consent, nil
+}
+
+// Save writes the consent file atomically
+func (s *FileStore) Save(ctx context.Context, consent *models.Consent) error {
+	if err := s.dir.Put(consent.Phone, consent); err != nil {
+		return fmt.Errorf("failed to write consent: %w", err)
+	}
+	return nil
+}
+
+// List reads every consent file in the directory
+func (s *FileStore) List(ctx context.Context) ([]*models.Consent, error) {
+	consents, err := jsonfile.List[models.Consent](s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consents: %w", err)
+	}
+	return consents, nil
+}
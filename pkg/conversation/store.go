@@ -0,0 +1,122 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Store persists conversations keyed by user ID
+type Store interface {
+	// Get returns the conversation for a user, or an empty one if none exists
+	Get(ctx context.Context, userID string) (*models.Conversation, error)
+	// Save stores the conversation
+	Save(ctx context.Context, conv *models.Conversation) error
+	// Delete removes the conversation for a user
+	Delete(ctx context.Context, userID string) error
+}
+
+// NewStore returns a file store in CONVERSATION_STORE_DIR, or an in-memory store when it is unset
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.ConversationStoreDir == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewFileStore(cfg.ConversationStoreDir)
+}
+
+// MemoryStore keeps conversations in process memory
+type MemoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string]*models.Conversation
+}
+
+// NewMemoryStore creates a new in-memory conversation store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		conversations: make(map[string]*models.Conversation),
+	}
+}
+
+// Get returns a copy of the stored conversation
+func (s *MemoryStore) Get(ctx context.Context, userID string) (*models.Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conv, ok := s.conversations[userID]
+	if !ok {
+		return &models.Conversation{UserID: userID}, nil
+	}
+	return copyConversation(conv), nil
+}
+
+// Save stores a copy of the conversation
+func (s *MemoryStore) Save(ctx context.Context, conv *models.Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conversations[conv.UserID] = copyConversation(conv)
+	return nil
+}
+
+// Delete removes the conversation for a user
+func (s *MemoryStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conversations, userID)
+	return nil
+}
+
+// FileStore keeps each conversation as a JSON file in a directory
+type FileStore struct {
+	dir *jsonfile.Dir
+}
+
+// NewFileStore creates a new file-backed conversation store
+func NewFileStore(dir string) (*FileStore, error) {
+	d, err := jsonfile.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation store directory: %w", err)
+	}
+	return &FileStore{dir: d}, nil
+}
+
+// Get reads the conversation file for a user
+func (s *FileStore) Get(ctx context.Context, userID string) (*models.Conversation, error) {
+	var conv models.Conversation
+	found, err := s.dir.Get(userID, &conv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+	if !found {
+		return &models.Conversation{UserID: userID}, nil
+	}
+	return &conv, nil
+}
+
+// Save writes the conversation file atomically
+func (s *FileStore) Save(ctx context.Context, conv *models.Conversation) error {
+	if err := s.dir.Put(conv.UserID, conv); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the conversation file for a user
+func (s *FileStore) Delete(ctx context.Context, userID string) error {
+	if err := s.dir.Delete(userID); err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	return nil
+}
+
+// copyConversation returns a deep copy so callers cannot mutate stored state
+func copyConversation(conv *models.Conversation) *models.Conversation {
+	c := *conv
+	c.Turns = append([]models.ConversationTurn(nil), conv.Turns...)
+	return &c
+}
@@ -0,0 +1,86 @@
+package document
+
+import (
+	"strings"
+)
+
+// Chunk splits text into chunks of at most size characters, packing whole paragraphs
+// where possible and repeating up to overlap characters of context between chunks
+func Chunk(text string, size, overlap int) []string {
+	if size <= 0 {
+		size = 1000
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	// Break the text into pieces no longer than the chunk size
+	var pieces []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		pieces = append(pieces, splitLong(paragraph, size)...)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	for _, piece := range pieces {
+		if current.Len() > 0 && current.Len()+len(piece)+2 > size {
+			chunk := current.String()
+			chunks = append(chunks, chunk)
+			current.Reset()
+			if tail := overlapTail(chunk, overlap); tail != "" && len(tail)+len(piece)+2 <= size {
+				current.WriteString(tail)
+			}
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitLong splits a paragraph longer than size at word boundaries
+func splitLong(paragraph string, size int) []string {
+	if len(paragraph) <= size {
+		return []string{paragraph}
+	}
+
+	var parts []string
+	var current strings.Builder
+	for _, word := range strings.Fields(paragraph) {
+		if current.Len() > 0 && current.Len()+len(word)+1 > size {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(word)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// overlapTail returns the last words of a chunk totalling at most overlap characters
+func overlapTail(chunk string, overlap int) string {
+	if overlap <= 0 {
+		return ""
+	}
+	if len(chunk) <= overlap {
+		return chunk
+	}
+	tail := chunk[len(chunk)-overlap:]
+	if idx := strings.IndexAny(tail, " \n"); idx >= 0 {
+		tail = tail[idx+1:]
+	}
+	return strings.TrimSpace(tail)
+}
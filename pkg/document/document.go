@@ -0,0 +1,29 @@
+package document
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedFormat is returned for files whose text cannot be extracted
+var ErrUnsupportedFormat = errors.New("unsupported document format")
+
+// ExtractText extracts the plain text of a document, choosing the format by file extension
+func ExtractText(filename string, data []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".pdf":
+		return ExtractPDFText(data)
+	case ".docx":
+		return ExtractDOCXText(data)
+	case ".txt", ".md", ".markdown", ".csv":
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("%s is not valid UTF-8 text", filepath.Base(filename))
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Base(filename))
+	}
+}
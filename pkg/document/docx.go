@@ -0,0 +1,83 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxDOCXPartSize limits the decompressed size of a DOCX document body
+const maxDOCXPartSize = 64 << 20
+
+// ExtractDOCXText extracts the text of a Word document's body, one paragraph per block.
+// Headers, footers and comments are left out.
+func ExtractDOCXText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", errors.New("not a DOCX file")
+	}
+
+	var body *zip.File
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			body = file
+			break
+		}
+	}
+	if body == nil {
+		return "", errors.New("not a DOCX file: word/document.xml is missing")
+	}
+
+	reader, err := body.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOCX body: %w", err)
+	}
+	defer reader.Close()
+
+	var text strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(reader, maxDOCXPartSize))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse DOCX body: %w", err)
+		}
+
+		// Word splits text into runs; <w:t> holds the text and paragraphs end with </w:p>
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteByte(' ')
+			case "br", "cr":
+				text.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteString("\n\n")
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+
+	result := normalizeWhitespace(text.String())
+	if result == "" {
+		return "", errors.New("no extractable text found in DOCX")
+	}
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package document
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// ExtractPDFText extracts the text drawn on the pages of a PDF. The parser decodes the stream
+// filters and font encodings, including ToUnicode maps of CID fonts; only page content streams
+// are read. Scanned PDFs have no text to extract.
+func ExtractPDFText(data []byte) (result string, err error) {
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		return "", errors.New("not a PDF file")
+	}
+
+	// The parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = "", fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	var text strings.Builder
+	fonts := make(map[string]pdf.TextEncoding)
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		contents := page.V.Key("Contents")
+		if contents.Kind() == pdf.Array {
+			for j := 0; j < contents.Len(); j++ {
+				extractPageText(page, contents.Index(j), fonts, &text)
+			}
+		} else {
+			extractPageText(page, contents, fonts, &text)
+		}
+		text.WriteString("\n\n")
+	}
+
+	result = normalizeWhitespace(text.String())
+	if result == "" {
+		return "", errors.New("no extractable text found in PDF")
+	}
+	return result, nil
+}
+
+// extractPageText interprets the text operators of a page content stream. Fonts are decoded
+// through the page's font resources and cached by resource name, which is per page in theory
+// but in practice names the same font throughout a document.
+func extractPageText(page pdf.Page, content pdf.Value, fonts map[string]pdf.TextEncoding, out *strings.Builder) {
+	if content.Kind() != pdf.Stream {
+		return
+	}
+
+	var enc pdf.TextEncoding = nopEncoding{}
+	show := func(s string) {
+		out.WriteString(enc.Decode(s))
+	}
+	pdf.Interpret(content, func(stk *pdf.Stack, op string) {
+		args := make([]pdf.Value, stk.Len())
+		for i := len(args) - 1; i >= 0; i-- {
+			args[i] = stk.Pop()
+		}
+
+		switch op {
+		case "Tf":
+			if len(args) != 2 {
+				return
+			}
+			name := args[0].Name()
+			encoding, ok := fonts[name]
+			if !ok {
+				encoding = page.Font(name).Encoder()
+				fonts[name] = encoding
+			}
+			enc = encoding
+		case "Td", "TD", "T*", "Tm":
+			out.WriteString("\n")
+		case "ET":
+			out.WriteString(" ")
+		case "'", "\"":
+			out.WriteString("\n")
+			if len(args) > 0 {
+				show(args[len(args)-1].RawString())
+			}
+		case "Tj":
+			if len(args) == 1 {
+				show(args[0].RawString())
+			}
+		case "TJ":
+			// Large negative adjustments are word gaps
+			if len(args) != 1 {
+				return
+			}
+			for i := 0; i < args[0].Len(); i++ {
+				item := args[0].Index(i)
+				switch item.Kind() {
+				case pdf.String:
+					show(item.RawString())
+				case pdf.Integer, pdf.Real:
+					if item.Float64() < -200 {
+						out.WriteString(" ")
+					}
+				}
+			}
+		}
+	})
+}
+
+// nopEncoding passes text through before any font is selected
+type nopEncoding struct{}
+
+// Decode returns the raw string
+func (nopEncoding) Decode(raw string) string {
+	return raw
+}
+
+// normalizeWhitespace trims lines and collapses runs of blank lines
+func normalizeWhitespace(text string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank && len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,72 @@
+package document
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExtractPDFText(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "simple font",
+			file: "testdata/simple.pdf",
+			want: []string{
+				"Return policy\nItems can be returned within 30 days.",
+				"Café opening hours: 08:00 - 17:00\nClosed on Sunday\n\nOpen daily",
+			},
+		},
+		{
+			name:    "CID font with ToUnicode map",
+			file:    "testdata/cid.pdf",
+			want:    []string{"Harga tiket Rp 50.000 – café"},
+			notWant: []string{"DECOY"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			text, err := ExtractPDFText(data)
+			if err != nil {
+				t.Fatalf("ExtractPDFText() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("ExtractPDFText() = %q, want it to contain %q", text, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(text, notWant) {
+					t.Errorf("ExtractPDFText() = %q, want no %q", text, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractPDFTextInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not a PDF", data: "hello"},
+		{name: "truncated", data: "%PDF-1.4\n1 0 obj\n<< /Type /Catalog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ExtractPDFText([]byte(tt.data)); err == nil {
+				t.Error("ExtractPDFText() error = nil, want an error")
+			}
+		})
+	}
+}
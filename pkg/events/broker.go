@@ -0,0 +1,123 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// subscriberBuffer is the number of events queued for a subscriber before it is dropped
+const subscriberBuffer = 64
+
+// Filter selects the events a subscriber receives
+type Filter func(event models.Event) bool
+
+// Subscription receives the events published after it was created
+type Subscription struct {
+	events chan models.Event
+	filter Filter
+}
+
+// Events returns the channel of events; it is closed when the subscription ends
+// or the subscriber falls too far behind
+func (s *Subscription) Events() <-chan models.Event {
+	return s.events
+}
+
+// Broker fans events out to subscribers and keeps the most recent ones so clients can resume
+type Broker struct {
+	mu          sync.Mutex
+	nextID      int64
+	buffer      []models.Event
+	size        int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroker creates a broker that keeps the last size events
+func NewBroker(size int) *Broker {
+	if size <= 0 {
+		size = 1
+	}
+	return &Broker{
+		// Start from the clock so IDs keep increasing across restarts
+		nextID:      time.Now().UnixMilli() * 1000,
+		size:        size,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and delivers it to matching subscribers
+func (b *Broker) Publish(eventType, userID string, data interface{}) models.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := models.Event{
+		ID:        b.nextID,
+		Type:      eventType,
+		UserID:    userID,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+
+	// Keep the most recent events for resuming clients
+	b.buffer = append(b.buffer, event)
+	if len(b.buffer) > b.size {
+		b.buffer = b.buffer[len(b.buffer)-b.size:]
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// Drop subscribers that can't keep up; they resume from their last event ID
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return event
+}
+
+// Subscribe starts a subscription and returns the buffered events after lastID that match filter.
+// missed is true when events after lastID have already left the buffer. A lastID of zero
+// subscribes to new events only.
+func (b *Broker) Subscribe(lastID int64, filter Filter) (sub *Subscription, backlog []models.Event, missed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastID > 0 {
+		// Events were missed if the oldest one kept is newer than the next one the client expects
+		if len(b.buffer) == 0 {
+			missed = lastID < b.nextID
+		} else {
+			missed = b.buffer[0].ID > lastID+1
+		}
+		for _, event := range b.buffer {
+			if event.ID > lastID && (filter == nil || filter(event)) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub = &Subscription{
+		events: make(chan models.Event, subscriberBuffer),
+		filter: filter,
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub, backlog, missed
+}
+
+// Unsubscribe ends a subscription
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,292 @@
+package flow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Step types, which decide how answers are validated
+const (
+	TypeText   = "text"
+	TypeNumber = "number"
+	TypePhone  = "phone"
+	TypeEmail  = "email"
+	TypeDate   = "date"
+	TypeChoice = "choice"
+)
+
+// End is the next step that completes a flow
+const End = "end"
+
+// maxButtons is the number of reply buttons WhatsApp shows with a message
+const maxButtons = 3
+
+// Flow errors
+var (
+	ErrNotFound      = errors.New("flow or step not found")
+	ErrInvalidAnswer = errors.New("invalid answer")
+)
+
+// Flow is a structured form the user fills in one question at a time
+type Flow struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Command starts the flow as /command; aliases and keywords work like those of other commands
+	Command  string   `json:"command,omitempty"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Steps    []Step   `json:"steps"`
+	// Completion is sent when the flow is complete; a localized thank-you is sent when empty
+	Completion string `json:"completion,omitempty"`
+	// WebhookURL receives the answers when the flow is complete, instead of the default webhook
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// WebhookHeaders are sent with the answers; values may reference environment variables as ${VAR}
+	WebhookHeaders map[string]string `json:"webhook_headers,omitempty"`
+}
+
+// Step is a question of a flow
+type Step struct {
+	// ID names the answer in the results
+	ID     string `json:"id"`
+	Prompt string `json:"prompt"`
+	// Type is one of text, number, phone, email, date or choice; defaults to text
+	Type string `json:"type,omitempty"`
+	// Buttons are offered as quick replies; a choice step accepts only them and stores the button ID
+	Buttons []models.WhatsAppButtonReply `json:"buttons,omitempty"`
+	// Pattern is a regular expression the answer must also match
+	Pattern string `json:"pattern,omitempty"`
+	// Error replaces the localized message sent when the answer is invalid
+	Error string `json:"error,omitempty"`
+	// Branches choose the next step from the answer; the first match wins
+	Branches []Branch `json:"branches,omitempty"`
+	// Next is the step after this one when no branch matches. It defaults to the following step,
+	// and "end" completes the flow early.
+	Next string `json:"next,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Branch jumps to another step when the answer equals a value, ignoring case
+type Branch struct {
+	Equals string `json:"equals"`
+	Next   string `json:"next"`
+}
+
+// flowsFile is the layout of the flows file
+type flowsFile struct {
+	Flows []Flow `json:"flows"`
+}
+
+// Registry holds the flows users can fill in
+type Registry struct {
+	flows []*Flow
+	names map[string]*Flow
+}
+
+// Load reads the flow definitions from a JSON file
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flows file: %w", err)
+	}
+
+	var file flowsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse flows file: %w", err)
+	}
+	return NewRegistry(file.Flows)
+}
+
+// NewRegistry validates the flows and prepares them for use
+func NewRegistry(flows []Flow) (*Registry, error) {
+	r := &Registry{names: make(map[string]*Flow, len(flows))}
+	for i := range flows {
+		f := &flows[i]
+		if err := f.compile(); err != nil {
+			return nil, err
+		}
+		if _, exists := r.names[f.Name]; exists {
+			return nil, fmt.Errorf("flow %q is defined twice", f.Name)
+		}
+		r.flows = append(r.flows, f)
+		r.names[f.Name] = f
+	}
+	return r, nil
+}
+
+// Get returns the flow with the given name
+func (r *Registry) Get(name string) (*Flow, bool) {
+	f, ok := r.names[name]
+	return f, ok
+}
+
+// Flows returns the flows in file order
+func (r *Registry) Flows() []*Flow {
+	return r.flows
+}
+
+// Summaries describes the flows to the assistant
+func (r *Registry) Summaries() []models.FlowSummary {
+	summaries := make([]models.FlowSummary, 0, len(r.flows))
+	for _, f := range r.flows {
+		summaries = append(summaries, models.FlowSummary{Name: f.Name, Description: f.Description})
+	}
+	return summaries
+}
+
+// Start begins a flow and returns the new state with the first question
+func (r *Registry) Start(name string, now time.Time) (*models.FlowState, *Step, error) {
+	f, ok := r.names[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: flow %q", ErrNotFound, name)
+	}
+
+	state := &models.FlowState{
+		Name:      f.Name,
+		Step:      f.Steps[0].ID,
+		Answers:   make(map[string]string),
+		StartedAt: now,
+	}
+	return state, &f.Steps[0], nil
+}
+
+// Current returns the flow and the step waiting for an answer. It fails with ErrNotFound when
+// the definitions changed since the flow was started.
+func (r *Registry) Current(state *models.FlowState) (*Flow, *Step, error) {
+	f, ok := r.names[state.Name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: flow %q", ErrNotFound, state.Name)
+	}
+	_, step, ok := f.step(state.Step)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: step %q of flow %q", ErrNotFound, state.Step, state.Name)
+	}
+	return f, step, nil
+}
+
+// Answer validates the user's answer to the current step and moves the state on. It returns the
+// next question, or nil when the flow is complete. An invalid answer fails with ErrInvalidAnswer,
+// returning the current step to ask again.
+func (r *Registry) Answer(state *models.FlowState, message models.Message) (*Step, error) {
+	f, step, err := r.Current(state)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := step.validate(message.Text, message.ReplyID)
+	if !ok {
+		return step, ErrInvalidAnswer
+	}
+	if state.Answers == nil {
+		state.Answers = make(map[string]string)
+	}
+	state.Answers[step.ID] = value
+
+	next := f.next(step, value)
+	if next == End {
+		return nil, nil
+	}
+	_, nextStep, _ := f.step(next)
+	state.Step = next
+	return nextStep, nil
+}
+
+// step looks up a step by ID
+func (f *Flow) step(id string) (int, *Step, bool) {
+	for i := range f.Steps {
+		if f.Steps[i].ID == id {
+			return i, &f.Steps[i], true
+		}
+	}
+	return 0, nil, false
+}
+
+// next returns the ID of the step following an answer
+func (f *Flow) next(step *Step, answer string) string {
+	for _, branch := range step.Branches {
+		if strings.EqualFold(branch.Equals, answer) {
+			return branch.Next
+		}
+	}
+	if step.Next != "" {
+		return step.Next
+	}
+
+	index, _, _ := f.step(step.ID)
+	if index+1 < len(f.Steps) {
+		return f.Steps[index+1].ID
+	}
+	return End
+}
+
+// compile validates a flow and prepares its steps
+func (f *Flow) compile() error {
+	if f.Name == "" {
+		return errors.New("flow name is required")
+	}
+	if len(f.Steps) == 0 {
+		return fmt.Errorf("flow %q has no steps", f.Name)
+	}
+
+	ids := make(map[string]bool, len(f.Steps))
+	for i := range f.Steps {
+		step := &f.Steps[i]
+		if step.ID == "" || step.ID == End {
+			return fmt.Errorf("flow %q has a step without a valid id", f.Name)
+		}
+		if ids[step.ID] {
+			return fmt.Errorf("flow %q has two steps with id %q", f.Name, step.ID)
+		}
+		ids[step.ID] = true
+
+		if step.Prompt == "" {
+			return fmt.Errorf("step %q of flow %q has no prompt", step.ID, f.Name)
+		}
+		if step.Type == "" {
+			step.Type = TypeText
+		}
+		switch step.Type {
+		case TypeText, TypeNumber, TypePhone, TypeEmail, TypeDate:
+		case TypeChoice:
+			if len(step.Buttons) == 0 {
+				return fmt.Errorf("choice step %q of flow %q has no buttons", step.ID, f.Name)
+			}
+		default:
+			return fmt.Errorf("step %q of flow %q has unknown type %q", step.ID, f.Name, step.Type)
+		}
+		if len(step.Buttons) > maxButtons {
+			return fmt.Errorf("step %q of flow %q has more than %d buttons", step.ID, f.Name, maxButtons)
+		}
+		if step.Pattern != "" {
+			re, err := regexp.Compile(step.Pattern)
+			if err != nil {
+				return fmt.Errorf("step %q of flow %q has an invalid pattern: %w", step.ID, f.Name, err)
+			}
+			step.pattern = re
+		}
+	}
+
+	// Every jump must land on a step of this flow
+	for _, step := range f.Steps {
+		targets := []string{step.Next}
+		for _, branch := range step.Branches {
+			if branch.Next == "" {
+				return fmt.Errorf("step %q of flow %q has a branch without a next step", step.ID, f.Name)
+			}
+			targets = append(targets, branch.Next)
+		}
+		for _, target := range targets {
+			if target != "" && target != End && !ids[target] {
+				return fmt.Errorf("step %q of flow %q goes to unknown step %q", step.ID, f.Name, target)
+			}
+		}
+	}
+	return nil
+}
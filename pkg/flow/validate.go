@@ -0,0 +1,133 @@
+package flow
+
+import (
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the format dates are stored in
+const dateLayout = "2006-01-02"
+
+// dateLayouts are the date formats users may answer with; numeric dates are read day first
+var dateLayouts = []string{
+	"2006-01-02",
+	"2/1/2006",
+	"2-1-2006",
+	"2.1.2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2 2006",
+	"Jan 2 2006",
+}
+
+// Phone numbers have at most 15 digits, and local numbers at least 8
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// validate checks an answer to the step and returns the value to store
+func (s *Step) validate(text, replyID string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", false
+	}
+
+	var value string
+	var ok bool
+	switch s.Type {
+	case TypeNumber:
+		value, ok = validateNumber(text)
+	case TypePhone:
+		value, ok = validatePhone(text)
+	case TypeEmail:
+		value, ok = validateEmail(text)
+	case TypeDate:
+		value, ok = validateDate(text)
+	case TypeChoice:
+		return s.validateChoice(text, replyID)
+	default:
+		value, ok = text, true
+	}
+	if !ok {
+		return "", false
+	}
+	if s.pattern != nil && !s.pattern.MatchString(text) {
+		return "", false
+	}
+	return value, true
+}
+
+// validateChoice accepts a tapped button, or the ID or label of a button typed by the user
+func (s *Step) validateChoice(text, replyID string) (string, bool) {
+	for _, button := range s.Buttons {
+		if replyID != "" && replyID == button.ID {
+			return button.ID, true
+		}
+	}
+	for _, button := range s.Buttons {
+		if strings.EqualFold(text, button.ID) || strings.EqualFold(text, button.Title) {
+			return button.ID, true
+		}
+	}
+	return "", false
+}
+
+// validateNumber accepts a number written with a decimal point or comma
+func validateNumber(text string) (string, bool) {
+	number, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64), true
+}
+
+// validatePhone accepts a phone number with the usual separators and stores its digits,
+// keeping a leading + of international numbers
+func validatePhone(text string) (string, bool) {
+	var b strings.Builder
+	for i, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+
+	phone := b.String()
+	digits := len(strings.TrimPrefix(phone, "+"))
+	if digits < minPhoneDigits || digits > maxPhoneDigits {
+		return "", false
+	}
+	return phone, true
+}
+
+// validateEmail accepts a bare email address with a domain name
+func validateEmail(text string) (string, bool) {
+	address, err := mail.ParseAddress(text)
+	if err != nil || address.Address != text {
+		return "", false
+	}
+	at := strings.LastIndex(text, "@")
+	if !strings.Contains(text[at+1:], ".") {
+		return "", false
+	}
+	return text, true
+}
+
+// validateDate accepts a date in one of the usual formats and stores it as YYYY-MM-DD
+func validateDate(text string) (string, bool) {
+	text = strings.Join(strings.Fields(strings.ReplaceAll(text, ",", " ")), " ")
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date.Format(dateLayout), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,88 @@
+package flow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// webhookAttempts is how many times a result is posted before giving up
+const webhookAttempts = 3
+
+// Notifier posts completed flows to their webhooks
+type Notifier struct {
+	client     *http.Client
+	defaultURL string
+	retryDelay time.Duration
+}
+
+// NewNotifier creates a notifier posting to defaultURL unless a flow has its own webhook
+func NewNotifier(defaultURL string, timeout time.Duration) *Notifier {
+	return &Notifier{
+		client:     &http.Client{Timeout: timeout},
+		defaultURL: defaultURL,
+		retryDelay: 2 * time.Second,
+	}
+}
+
+// Post sends the answers of a completed flow to its webhook, retrying failed attempts.
+// It does nothing when no webhook is configured.
+func (n *Notifier) Post(ctx context.Context, f *Flow, result models.FlowResult) error {
+	url := f.WebhookURL
+	if url == "" {
+		url = n.defaultURL
+	}
+	if url == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal flow result: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = n.post(ctx, url, f.WebhookHeaders, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+
+		// Back off before trying again
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * n.retryDelay):
+		}
+	}
+}
+
+// post makes one delivery attempt
+func (n *Notifier) post(ctx context.Context, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call flow webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("flow webhook returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package hours
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// dateLayout is the format of holiday dates
+const dateLayout = "2006-01-02"
+
+// maxLookahead bounds the search for the next opening, so a calendar of holidays can't loop forever
+const maxLookahead = 366
+
+// dayNames maps the day names used in schedules to weekdays
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// span is an opening period within a day, in minutes since midnight
+type span struct {
+	start int
+	end   int
+}
+
+// Calendar holds the weekly opening hours and holidays of the business, in its timezone
+type Calendar struct {
+	schedule string
+	loc      *time.Location
+	weekly   [7][]span
+	holidays map[string]bool
+}
+
+// Parse creates a calendar from a weekly schedule such as "mon-fri 09:00-17:00; sat 09:00-13:00",
+// holiday dates formatted 2006-01-02 and the timezone the hours are in. A day may have several
+// periods, e.g. "mon-fri 09:00-12:00 13:00-17:00"; days not listed are closed.
+func Parse(schedule string, holidays []string, loc *time.Location) (*Calendar, error) {
+	c := &Calendar{
+		schedule: strings.TrimSpace(schedule),
+		loc:      loc,
+		holidays: make(map[string]bool, len(holidays)),
+	}
+
+	for _, entry := range strings.Split(schedule, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("business hours entry %q needs days and at least one time range", strings.TrimSpace(entry))
+		}
+
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields[1:] {
+			s, err := parseSpan(field)
+			if err != nil {
+				return nil, err
+			}
+			for _, day := range days {
+				c.weekly[day] = append(c.weekly[day], s)
+			}
+		}
+	}
+
+	// NextOpen takes the first period starting later, so a day's periods must be in time order
+	open := false
+	for _, spans := range c.weekly {
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+		open = open || len(spans) > 0
+	}
+	if !open {
+		return nil, fmt.Errorf("business hours %q have no opening times", schedule)
+	}
+
+	for _, holiday := range holidays {
+		date, err := time.Parse(dateLayout, strings.TrimSpace(holiday))
+		if err != nil {
+			return nil, fmt.Errorf("holiday %q must be formatted 2006-01-02", holiday)
+		}
+		c.holidays[date.Format(dateLayout)] = true
+	}
+	return c, nil
+}
+
+// IsOpen reports whether the business is open at t
+func (c *Calendar) IsOpen(t time.Time) bool {
+	t = t.In(c.loc)
+	if c.holidays[t.Format(dateLayout)] {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	for _, s := range c.weekly[t.Weekday()] {
+		if minute >= s.start && minute < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns when the business next opens after t, or t itself if it is open.
+// It returns the zero time if the business doesn't open within a year.
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	if c.IsOpen(t) {
+		return t
+	}
+	t = t.In(c.loc)
+	for i := 0; i < maxLookahead; i++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+i, 0, 0, 0, 0, c.loc)
+		if c.holidays[day.Format(dateLayout)] {
+			continue
+		}
+		for _, s := range c.weekly[day.Weekday()] {
+			start := c.at(day, s.start)
+			if start.After(t) {
+				return start
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// NextClose returns when the current opening period ends, or the zero time if the business is closed
+func (c *Calendar) NextClose(t time.Time) time.Time {
+	if !c.IsOpen(t) {
+		return time.Time{}
+	}
+	t = t.In(c.loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+	minute := t.Hour()*60 + t.Minute()
+	for _, s := range c.weekly[t.Weekday()] {
+		if minute >= s.start && minute < s.end {
+			return c.at(day, s.end)
+		}
+	}
+	return time.Time{}
+}
+
+// Status describes whether the business is open at t and when that changes
+func (c *Calendar) Status(t time.Time) *models.BusinessHours {
+	status := &models.BusinessHours{
+		Open:     c.IsOpen(t),
+		Schedule: c.schedule,
+		Timezone: c.loc.String(),
+	}
+	if status.Open {
+		if closes := c.NextClose(t); !closes.IsZero() {
+			status.ClosesAt = &closes
+		}
+	} else if opens := c.NextOpen(t); !opens.IsZero() {
+		status.OpensAt = &opens
+	}
+	return status
+}
+
+// at returns the given minute of a day in the calendar's timezone
+func (c *Calendar) at(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minute, 0, 0, c.loc)
+}
+
+// parseDays parses a day such as "mon", a range such as "mon-fri" or a list such as "mon,wed"
+func parseDays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(strings.ToLower(value), ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := dayNames[first]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q in business hours", first)
+		}
+		if !isRange {
+			days = append(days, from)
+			continue
+		}
+		to, ok := dayNames[last]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q in business hours", last)
+		}
+
+		// Ranges may wrap around the week, e.g. "sat-mon"
+		for day := from; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseSpan parses a time range such as "09:00-17:00"; the end may be 24:00
+func parseSpan(value string) (span, error) {
+	first, last, ok := strings.Cut(value, "-")
+	if !ok {
+		return span{}, fmt.Errorf("time range %q in business hours must look like 09:00-17:00", value)
+	}
+	start, err := parseMinute(first)
+	if err != nil {
+		return span{}, err
+	}
+	end, err := parseMinute(last)
+	if err != nil {
+		return span{}, err
+	}
+	if end <= start {
+		return span{}, fmt.Errorf("time range %q in business hours must end after it starts", value)
+	}
+	return span{start: start, end: end}, nil
+}
+
+// parseMinute parses a time of day such as "09:30" into minutes since midnight
+func parseMinute(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid time %q in business hours", value)
+	}
+	return hour*60 + minute, nil
+}
@@ -0,0 +1,266 @@
+package hours
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // The DST cases need America/New_York wherever the tests run
+)
+
+// wib is Western Indonesian Time, which has no daylight saving time
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// testSchedule has a lunch break on weekdays, a short Saturday and a Wednesday holiday
+const testSchedule = "mon-fri 09:00-12:00 13:00-17:00; sat 09:00-13:00"
+
+// newTestCalendar parses a schedule or fails the test
+func newTestCalendar(t *testing.T, schedule string, holidays []string, loc *time.Location) *Calendar {
+	t.Helper()
+
+	c, err := Parse(schedule, holidays, loc)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", schedule, err)
+	}
+	return c
+}
+
+// at parses a local time such as "2026-10-19 09:00" in loc
+func at(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+
+	v, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatalf("invalid test time %q: %v", value, err)
+	}
+	return v
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		holidays []string
+		wantErr  bool
+	}{
+		// Valid schedules
+		{name: "one entry", schedule: "mon-fri 09:00-17:00"},
+		{name: "several periods", schedule: testSchedule},
+		{name: "day list", schedule: "mon,wed,fri 10:00-14:00"},
+		{name: "upper case days", schedule: "MON-FRI 09:00-17:00"},
+		{name: "until midnight", schedule: "fri 20:00-24:00"},
+		{name: "empty entries", schedule: "; mon 09:00-17:00 ;"},
+		{name: "holidays", schedule: "mon 09:00-17:00", holidays: []string{"2026-12-25", " 2027-01-01 "}},
+
+		// Invalid schedules
+		{name: "empty", schedule: "", wantErr: true},
+		{name: "only separators", schedule: " ; ; ", wantErr: true},
+		{name: "no time range", schedule: "mon", wantErr: true},
+		{name: "unknown day", schedule: "funday 09:00-17:00", wantErr: true},
+		{name: "unknown range end", schedule: "mon-xyz 09:00-17:00", wantErr: true},
+		{name: "range without a dash", schedule: "mon 09:00", wantErr: true},
+		{name: "hours without minutes", schedule: "mon 9-17", wantErr: true},
+		{name: "ends before it starts", schedule: "mon 17:00-09:00", wantErr: true},
+		{name: "empty range", schedule: "mon 09:00-09:00", wantErr: true},
+		{name: "after midnight", schedule: "mon 09:00-24:30", wantErr: true},
+		{name: "invalid minute", schedule: "mon 09:60-17:00", wantErr: true},
+		{name: "invalid holiday", schedule: "mon 09:00-17:00", holidays: []string{"25-12-2026"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.schedule, tt.holidays, wib)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q, %q) error = %v, want error %v", tt.schedule, tt.holidays, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []time.Weekday
+		wantErr bool
+	}{
+		{value: "mon", want: []time.Weekday{time.Monday}},
+		{value: "mon-fri", want: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}},
+		{value: "mon,wed", want: []time.Weekday{time.Monday, time.Wednesday}},
+		{value: "fri-fri", want: []time.Weekday{time.Friday}},
+		{value: "Sat-Sun", want: []time.Weekday{time.Saturday, time.Sunday}},
+
+		// Ranges wrap around the week
+		{value: "sat-mon", want: []time.Weekday{time.Saturday, time.Sunday, time.Monday}},
+		{value: "fri-tue", want: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday, time.Tuesday}},
+		{value: "sun-sat", want: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}},
+		{value: "sat-mon,wed", want: []time.Weekday{time.Saturday, time.Sunday, time.Monday, time.Wednesday}},
+
+		{value: "", wantErr: true},
+		{value: "monday", wantErr: true},
+		{value: "mon-", wantErr: true},
+		{value: "mon,,wed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDays(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDays(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDays(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	// 2026-10-19 is a Monday and the Wednesday after it a holiday
+	c := newTestCalendar(t, testSchedule, []string{"2026-10-21"}, wib)
+
+	tests := []struct {
+		name string
+		now  string
+		want string
+	}{
+		{name: "open", now: "2026-10-19 10:00", want: "2026-10-19 10:00"},
+		{name: "at opening", now: "2026-10-19 09:00", want: "2026-10-19 09:00"},
+		{name: "before opening", now: "2026-10-19 08:00", want: "2026-10-19 09:00"},
+		{name: "lunch break", now: "2026-10-19 12:00", want: "2026-10-19 13:00"},
+		{name: "at closing", now: "2026-10-19 17:00", want: "2026-10-20 09:00"},
+		{name: "evening before a holiday", now: "2026-10-20 18:00", want: "2026-10-22 09:00"},
+		{name: "during a holiday", now: "2026-10-21 10:00", want: "2026-10-22 09:00"},
+		{name: "saturday afternoon", now: "2026-10-24 13:00", want: "2026-10-26 09:00"},
+		{name: "sunday", now: "2026-10-25 10:00", want: "2026-10-26 09:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := at(t, tt.want, wib)
+			if got := c.NextOpen(at(t, tt.now, wib)); !got.Equal(want) {
+				t.Errorf("NextOpen(%s) = %v, want %v", tt.now, got, want)
+			}
+		})
+	}
+
+	// Times in other zones are read in the calendar's
+	if got, want := c.NextOpen(time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC)), at(t, "2026-10-19 09:00", wib); !got.Equal(want) {
+		t.Errorf("NextOpen(01:00 UTC) = %v, want %v", got, want)
+	}
+
+	// Periods are tried in time order, whatever order they are listed in
+	split := newTestCalendar(t, "mon-fri 13:00-17:00; mon 09:00-12:00", nil, wib)
+	if got, want := split.NextOpen(at(t, "2026-10-19 08:00", wib)), at(t, "2026-10-19 09:00", wib); !got.Equal(want) {
+		t.Errorf("NextOpen() with periods out of order = %v, want %v", got, want)
+	}
+
+	// A calendar whose only day is always a holiday never opens
+	never := newTestCalendar(t, "mon 09:00-17:00", nil, wib)
+	for i := 0; i < maxLookahead; i++ {
+		never.holidays[at(t, "2026-10-19 00:00", wib).AddDate(0, 0, i).Format(dateLayout)] = true
+	}
+	if got := never.NextOpen(at(t, "2026-10-19 08:00", wib)); !got.IsZero() {
+		t.Errorf("NextOpen() without an opening in a year = %v, want the zero time", got)
+	}
+}
+
+func TestNextClose(t *testing.T) {
+	c := newTestCalendar(t, testSchedule, []string{"2026-10-21"}, wib)
+
+	tests := []struct {
+		name string
+		now  string
+		want string
+	}{
+		{name: "morning", now: "2026-10-19 10:00", want: "2026-10-19 12:00"},
+		{name: "last minute", now: "2026-10-19 11:59", want: "2026-10-19 12:00"},
+		{name: "afternoon", now: "2026-10-19 13:00", want: "2026-10-19 17:00"},
+		{name: "saturday", now: "2026-10-24 09:00", want: "2026-10-24 13:00"},
+
+		// Closed times have no closing time
+		{name: "lunch break", now: "2026-10-19 12:00", want: ""},
+		{name: "holiday", now: "2026-10-21 10:00", want: ""},
+		{name: "sunday", now: "2026-10-25 10:00", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.NextClose(at(t, tt.now, wib))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("NextClose(%s) = %v, want the zero time", tt.now, got)
+				}
+				return
+			}
+			if want := at(t, tt.want, wib); !got.Equal(want) {
+				t.Errorf("NextClose(%s) = %v, want %v", tt.now, got, want)
+			}
+		})
+	}
+}
+
+func TestMidnight(t *testing.T) {
+	// A late shift ends at 24:00 and the next day's starts at midnight
+	c := newTestCalendar(t, "fri 20:00-24:00; sat 00:00-02:00", nil, wib)
+
+	if !c.IsOpen(at(t, "2026-10-23 23:59", wib)) {
+		t.Error("IsOpen(fri 23:59) = false, want true")
+	}
+	if got, want := c.NextClose(at(t, "2026-10-23 23:00", wib)), at(t, "2026-10-24 00:00", wib); !got.Equal(want) {
+		t.Errorf("NextClose(fri 23:00) = %v, want midnight %v", got, want)
+	}
+	if got, want := c.NextClose(at(t, "2026-10-24 00:00", wib)), at(t, "2026-10-24 02:00", wib); !got.Equal(want) {
+		t.Errorf("NextClose(sat 00:00) = %v, want %v", got, want)
+	}
+	if got, want := c.NextOpen(at(t, "2026-10-24 03:00", wib)), at(t, "2026-10-30 20:00", wib); !got.Equal(want) {
+		t.Errorf("NextOpen(sat 03:00) = %v, want %v", got, want)
+	}
+}
+
+func TestDaylightSavingTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	c := newTestCalendar(t, "mon-fri 09:00-17:00; sat 20:00-24:00", nil, newYork)
+
+	tests := []struct {
+		name string
+		got  func() time.Time
+		// want is in UTC, so an hour's error around the change is caught
+		want time.Time
+	}{
+		{
+			name: "opening after clocks go forward",
+			got:  func() time.Time { return c.NextOpen(at(t, "2026-03-08 01:00", newYork)) },
+			want: time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "closing after clocks go forward",
+			got:  func() time.Time { return c.NextClose(at(t, "2026-03-09 10:00", newYork)) },
+			want: time.Date(2026, 3, 9, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "midnight before clocks go forward",
+			got:  func() time.Time { return c.NextClose(at(t, "2026-03-07 21:00", newYork)) },
+			want: time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "opening after clocks go back",
+			got:  func() time.Time { return c.NextOpen(at(t, "2026-11-01 03:00", newYork)) },
+			want: time.Date(2026, 11, 2, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "midnight before clocks go back",
+			got:  func() time.Time { return c.NextClose(at(t, "2026-10-31 21:00", newYork)) },
+			want: time.Date(2026, 11, 1, 4, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(); !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got.UTC(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minMarkers is the number of marker words a message needs before its language is trusted
+const minMarkers = 2
+
+// markers are common words that give away the language of a message. Words Indonesian and
+// Javanese share, such as "apa", "bisa" or "kapan", are left out.
+var markers = map[string][]string{
+	English: {
+		"i", "me", "my", "you", "your", "we", "our", "it", "the", "an", "is", "are", "was", "were",
+		"be", "do", "does", "did", "have", "has", "can", "could", "would", "will", "what", "how",
+		"when", "where", "why", "which", "this", "that", "there", "of", "to", "in", "on", "for",
+		"with", "from", "about", "and", "not", "please", "thanks", "thank", "want", "need", "help",
+		"know", "get", "order",
+	},
+	Indonesian: {
+		"saya", "anda", "kamu", "kami", "kita", "mereka", "apakah", "bagaimana", "gimana", "berapa",
+		"dimana", "mana", "kenapa", "mengapa", "tidak", "nggak", "enggak", "sudah", "udah", "belum",
+		"sedang", "yang", "dan", "atau", "dengan", "untuk", "dari", "di", "ke", "ini", "itu", "ada",
+		"akan", "mau", "ingin", "minta", "pesanan", "terima", "kasih", "mohon", "bantu", "buka",
+		"harus", "juga", "saja", "sangat", "bapak", "selamat", "pagi", "malam", "tanya", "dong",
+		"sih", "deh", "nih",
+	},
+	Javanese: {
+		"kula", "kulo", "kowe", "sampeyan", "panjenengan", "jenengan", "opo", "nopo", "menapa",
+		"punapa", "piye", "kepiye", "pripun", "ora", "mboten", "iki", "iku", "kuwi", "niki", "niku",
+		"punika", "wis", "sampun", "arep", "ajeng", "badhe", "karo", "kaliyan", "kalih", "lan",
+		"utawa", "utawi", "sing", "ingkang", "ning", "neng", "teng", "ing", "ana", "wonten", "saka",
+		"seko", "saking", "matur", "nuwun", "suwun", "nyuwun", "njaluk", "tulung", "pinten", "piro",
+		"pira", "endi", "ngendi", "pundi", "saiki", "sakniki", "sakmenika", "mengko", "mangke",
+		"ngerti", "mangertos", "wae", "mawon", "kemawon", "sanget", "durung", "dereng", "kudu",
+		"kedah", "iso", "isa", "saged", "ugi", "uga", "maneh", "malih", "dhewe", "gawe", "damel",
+		"nggih", "inggih", "enggih", "sugeng", "enjing", "dalu", "tumbas", "tuku", "regane",
+		"reginipun", "bukak",
+	},
+}
+
+// markerLanguages maps each marker word to its language
+var markerLanguages = func() map[string]string {
+	index := make(map[string]string)
+	for lang, words := range markers {
+		for _, word := range words {
+			index[word] = lang
+		}
+	}
+	return index
+}()
+
+// Detect guesses the language of a message from the common words in it. It returns "" when
+// the message is too short or too mixed to tell.
+func Detect(text string) string {
+	counts := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if lang, ok := markerLanguages[word]; ok {
+			counts[lang]++
+		}
+	}
+
+	// Trust only a clear winner
+	best, bestCount, runnerUp := "", 0, 0
+	for lang, count := range counts {
+		switch {
+		case count > bestCount:
+			best, bestCount, runnerUp = lang, count, bestCount
+		case count > runnerUp:
+			runnerUp = count
+		}
+	}
+	if bestCount < minMarkers || bestCount == runnerUp {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,64 @@
+package i18n
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		// Indonesian
+		{name: "id question", text: "Saya mau tanya, pesanan saya sudah dikirim belum?", want: Indonesian},
+		{name: "id greeting", text: "Selamat pagi, apakah toko buka hari ini?", want: Indonesian},
+		{name: "id thanks", text: "Terima kasih banyak ya", want: Indonesian},
+		{name: "id upper case", text: "SAYA MAU PESAN!!!", want: Indonesian},
+
+		// Javanese, both ngoko and krama
+		{name: "jv ngoko", text: "Aku arep takon, pesenanku wis dikirim durung?", want: Javanese},
+		{name: "jv krama", text: "Nyuwun sewu, menapa toko niki sampun bukak?", want: Javanese},
+		{name: "jv thanks", text: "Matur nuwun nggih", want: Javanese},
+		{name: "jv price", text: "Regane pinten mas?", want: Javanese},
+		{name: "jv with shared words", text: "apa kowe wis mangan?", want: Javanese},
+
+		// English
+		{name: "en question", text: "Where is my order? I need help please", want: English},
+		{name: "en thanks", text: "Thank you for the quick reply", want: English},
+
+		// Short messages don't have enough markers
+		{name: "empty", text: "", want: ""},
+		{name: "ok", text: "ok", want: ""},
+		{name: "greeting only", text: "halo", want: ""},
+		{name: "one marker", text: "pesanan", want: ""},
+		{name: "one english marker", text: "thanks!", want: ""},
+		{name: "no letters", text: "123 !!!", want: ""},
+		{name: "shared id and jv words only", text: "bisa kirim kapan?", want: ""},
+
+		// Mixed messages need a clear winner
+		{name: "en and id tie", text: "thank you, terima kasih", want: ""},
+		{name: "id and jv tie", text: "saya mau, kula badhe", want: ""},
+		{name: "id with an english word", text: "Saya mau order yang ini", want: Indonesian},
+		{name: "jv with an indonesian word", text: "kula badhe tumbas pesanan", want: Javanese},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// A word listed for two languages would be counted for whichever was indexed last
+func TestMarkersAreUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for lang, words := range markers {
+		for _, word := range words {
+			if other, ok := seen[word]; ok && other != lang {
+				t.Errorf("marker %q is listed for both %s and %s", word, other, lang)
+			}
+			seen[word] = lang
+		}
+	}
+}
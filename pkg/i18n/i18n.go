@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Supported language codes
+const (
+	English    = "en"
+	Indonesian = "id"
+	Javanese   = "jv"
+)
+
+// languages maps each supported code to its English and native names
+var languages = map[string][]string{
+	English:    {"English", "Inggris"},
+	Indonesian: {"Indonesian", "Bahasa Indonesia", "Indonesia"},
+	Javanese:   {"Javanese", "Basa Jawa", "Jawa", "Jawi"},
+}
+
+// Supported reports whether a language code is supported
+func Supported(code string) bool {
+	_, ok := languages[code]
+	return ok
+}
+
+// Name returns the English name of a language, or the code if it is unknown
+func Name(code string) string {
+	if names, ok := languages[code]; ok {
+		return names[0]
+	}
+	return code
+}
+
+// Codes returns the supported language codes in sorted order
+func Codes() []string {
+	codes := make([]string, 0, len(languages))
+	for code := range languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Parse resolves a language code or name, such as "id" or "bahasa indonesia", to a supported code
+func Parse(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for code, names := range languages {
+		if s == code {
+			return code, true
+		}
+		for _, name := range names {
+			if s == strings.ToLower(name) {
+				return code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// T returns the message for key in the given language, formatted with args.
+// It falls back to English, and then to the key itself, when no translation exists.
+func T(lang, key string, args ...interface{}) string {
+	text, ok := catalog[lang][key]
+	if !ok {
+		text, ok = catalog[English][key]
+	}
+	if !ok {
+		text = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
@@ -0,0 +1,206 @@
+package i18n
+
+// catalog holds the user-facing messages of each language
+var catalog = map[string]map[string]string{
+	English: {
+		"command.help":     "Show this list of commands",
+		"command.reset":    "Forget our conversation, cancel any form or agent request and start over",
+		"command.lang":     "Change the reply language, e.g. /lang id",
+		"command.human":    "Ask to talk to a person",
+		"command.timezone": "Set your timezone for reminders, e.g. /timezone Asia/Jakarta",
+		"command.unknown":  "I don't know the command %s. Send /help to see what I can do.",
+		"command.failed":   "Sorry, something went wrong. Please try again.",
+		"help.header":      "Here's what you can send me:",
+		"help.footer":      "Anything else is answered by the assistant.",
+		"reset.done":       "Done! I've forgotten our conversation and cancelled any form or agent request. How can I help?",
+		"lang.current":     "I'm replying in %s. Available languages: %s. Send /lang followed by a code to change it, or /lang auto to follow the language you write in.",
+		"lang.changed":     "Okay, I'll reply in English from now on.",
+		"lang.unsupported": "Sorry, I don't support %q. Available languages: %s.",
+		"timezone.current": "Your timezone is %s. Send /timezone followed by a name such as Asia/Jakarta or WIB to change it.",
+		"timezone.changed": "Okay, your timezone is now %s. It's %s there.",
+		"timezone.unknown": "Sorry, I don't know the timezone %q. Try a name such as Asia/Jakarta, or WIB, WITA or WIT.",
+		"human.requested":  "Thanks, I've asked a member of our team to get in touch with you here.",
+		"handoff.started":  "Sorry, I'm having trouble helping with this. I've asked a member of our team to take over.",
+		"handoff.ended":    "You're chatting with the assistant again. Send /human if you need our team.",
+		"handoff.closed":   "This conversation has been closed. Send a message anytime if you need more help.",
+		"handoff.queued":   "Sorry, I'm having trouble helping with this. Our team is away right now, so I've asked them to pick up this conversation when they're back at %s.",
+		"handoff.dequeued": "Our team is back and will continue this conversation with you shortly.",
+		"human.queued":     "Our team is away right now. I've added you to the queue and they'll get in touch when they're back at %s. Meanwhile, I'm happy to keep helping.",
+		"hours.closed":     "Our team is away right now and will be back at %s. I can still help with most questions in the meantime.",
+		"consent.stopped":  "You've been unsubscribed and won't get any more messages from us. Reply %s to subscribe again.",
+		"consent.started":  "You're subscribed again. Reply %s anytime to stop receiving messages.",
+
+		"command.cancel":      "Stop filling in the current form",
+		"flow.cancelled":      "Okay, I've stopped the form. Your answers weren't sent.",
+		"flow.none":           "There's no form in progress.",
+		"flow.completed":      "Thank you, your answers have been received.",
+		"flow.invalid.text":   "Please type an answer.",
+		"flow.invalid.number": "That doesn't look like a number. Please send digits only, e.g. 12.",
+		"flow.invalid.phone":  "That doesn't look like a phone number. Please send it with digits only, e.g. +62 812 3456 7890.",
+		"flow.invalid.email":  "That doesn't look like an email address. Please send it like name@example.com.",
+		"flow.invalid.date":   "I couldn't read that date. Please send it as day/month/year, e.g. 17/08/2024.",
+		"flow.invalid.choice": "Please choose one of the options.",
+
+		"voice.heard":       "I heard: %s",
+		"voice.unsupported": "Sorry, I can't listen to voice notes. Please type your message instead.",
+		"voice.failed":      "Sorry, I couldn't make out that voice note. Please try again or type your message.",
+		"voice.too_long":    "Sorry, that voice note is too long for me. Please send a shorter one or type your message.",
+
+		"image.failed":    "Sorry, I couldn't open that image. Please try sending it again.",
+		"image.too_large": "Sorry, that image is too large for me. Please send a smaller one.",
+
+		"document.received":    "I've read %s. Ask me anything about it.",
+		"document.unsupported": "Sorry, I can't read %s. Please send a PDF or Word (.docx) document.",
+		"document.too_large":   "Sorry, %s is too large for me. Please send a smaller file.",
+		"document.too_long":    "Sorry, %s has too much text for me. Please send a shorter document or just the pages you need.",
+		"document.unreadable":  "Sorry, I couldn't find any text in %s. Scanned documents aren't supported, so please send one with selectable text.",
+		"document.failed":      "Sorry, I couldn't open %s. Please try sending it again.",
+
+		"command.voice":        "Choose voice or text replies, e.g. /voice on",
+		"speech.disabled":      "Sorry, I can only reply in text.",
+		"speech.usage":         "Send /voice on for voice replies, /voice off for text replies, or /voice auto to get voice replies to your voice notes.",
+		"speech.current.text":  "I'm replying in text. Send /voice on for voice replies.",
+		"speech.current.voice": "I'm replying with voice notes. Send /voice off for text replies.",
+		"speech.current.auto":  "I'm replying to voice notes with voice notes and to text with text.",
+		"speech.changed.text":  "Okay, I'll reply in text.",
+		"speech.changed.voice": "Okay, I'll reply with voice notes.",
+		"speech.changed.auto":  "Okay, I'll answer your voice notes with voice notes and your text with text.",
+
+		"reply.failed": "Sorry, I'm having trouble processing your message right now.",
+		"lang.auto":    "Okay, I'll reply in the language you write in.",
+	},
+	Indonesian: {
+		"command.help":     "Tampilkan daftar perintah ini",
+		"command.reset":    "Lupakan percakapan kita, batalkan formulir atau permintaan petugas, dan mulai dari awal",
+		"command.lang":     "Ganti bahasa balasan, mis. /lang en",
+		"command.human":    "Minta berbicara dengan petugas",
+		"command.timezone": "Atur zona waktu untuk pengingat, mis. /zonawaktu WIB",
+		"command.unknown":  "Saya tidak mengenal perintah %s. Kirim /bantuan untuk melihat apa yang bisa saya lakukan.",
+		"command.failed":   "Maaf, terjadi kesalahan. Silakan coba lagi.",
+		"help.header":      "Berikut perintah yang bisa Anda kirim:",
+		"help.footer":      "Pesan lainnya akan dijawab oleh asisten.",
+		"reset.done":       "Selesai! Percakapan kita sudah saya lupakan dan formulir atau permintaan petugas sudah dibatalkan. Ada yang bisa saya bantu?",
+		"lang.current":     "Saya membalas dalam %s. Bahasa yang tersedia: %s. Kirim /lang diikuti kode bahasa untuk menggantinya, atau /lang auto agar saya mengikuti bahasa yang Anda gunakan.",
+		"lang.changed":     "Baik, mulai sekarang saya akan membalas dalam Bahasa Indonesia.",
+		"lang.unsupported": "Maaf, saya tidak mendukung %q. Bahasa yang tersedia: %s.",
+		"timezone.current": "Zona waktu Anda adalah %s. Kirim /zonawaktu diikuti nama zona seperti Asia/Jakarta atau WIB untuk menggantinya.",
+		"timezone.changed": "Baik, zona waktu Anda sekarang %s. Di sana sekarang pukul %s.",
+		"timezone.unknown": "Maaf, saya tidak mengenal zona waktu %q. Coba nama seperti Asia/Jakarta, atau WIB, WITA, WIT.",
+		"human.requested":  "Terima kasih, saya sudah meminta tim kami untuk menghubungi Anda di sini.",
+		"handoff.started":  "Maaf, saya kesulitan membantu hal ini. Saya sudah meminta tim kami untuk melanjutkan.",
+		"handoff.ended":    "Anda kembali terhubung dengan asisten. Kirim /human jika membutuhkan tim kami.",
+		"handoff.closed":   "Percakapan ini telah ditutup. Kirim pesan kapan saja jika Anda butuh bantuan lagi.",
+		"handoff.queued":   "Maaf, saya kesulitan membantu hal ini. Tim kami sedang tidak bertugas, jadi saya sudah meminta mereka melanjutkan percakapan ini saat kembali pada %s.",
+		"handoff.dequeued": "Tim kami sudah kembali dan akan segera melanjutkan percakapan ini dengan Anda.",
+		"human.queued":     "Tim kami sedang tidak bertugas. Anda sudah masuk antrean dan mereka akan menghubungi Anda saat kembali pada %s. Sementara itu, saya siap membantu.",
+		"hours.closed":     "Tim kami sedang tidak bertugas dan akan kembali pada %s. Sementara itu saya tetap bisa membantu sebagian besar pertanyaan Anda.",
+		"consent.stopped":  "Anda telah berhenti berlangganan dan tidak akan menerima pesan lagi dari kami. Balas %s untuk berlangganan kembali.",
+		"consent.started":  "Anda kembali berlangganan. Balas %s kapan saja untuk berhenti menerima pesan.",
+
+		"command.cancel":      "Berhenti mengisi formulir yang sedang berjalan",
+		"flow.cancelled":      "Baik, formulir sudah saya hentikan. Jawaban Anda tidak dikirim.",
+		"flow.none":           "Tidak ada formulir yang sedang diisi.",
+		"flow.completed":      "Terima kasih, jawaban Anda sudah kami terima.",
+		"flow.invalid.text":   "Silakan ketik jawaban Anda.",
+		"flow.invalid.number": "Sepertinya itu bukan angka. Silakan kirim angka saja, mis. 12.",
+		"flow.invalid.phone":  "Sepertinya itu bukan nomor telepon. Silakan kirim dengan angka saja, mis. 0812 3456 7890.",
+		"flow.invalid.email":  "Sepertinya itu bukan alamat email. Silakan kirim seperti nama@contoh.com.",
+		"flow.invalid.date":   "Saya tidak bisa membaca tanggal itu. Silakan kirim sebagai tanggal/bulan/tahun, mis. 17/08/2024.",
+		"flow.invalid.choice": "Silakan pilih salah satu opsi.",
+
+		"voice.heard":       "Saya mendengar: %s",
+		"voice.unsupported": "Maaf, saya tidak bisa mendengarkan pesan suara. Silakan ketik pesan Anda.",
+		"voice.failed":      "Maaf, saya tidak bisa memahami pesan suara itu. Silakan coba lagi atau ketik pesan Anda.",
+		"voice.too_long":    "Maaf, pesan suara itu terlalu panjang. Silakan kirim yang lebih pendek atau ketik pesan Anda.",
+
+		"image.failed":    "Maaf, saya tidak bisa membuka gambar itu. Silakan coba kirim lagi.",
+		"image.too_large": "Maaf, gambar itu terlalu besar. Silakan kirim yang lebih kecil.",
+
+		"document.received":    "Saya sudah membaca %s. Silakan tanyakan apa saja tentang dokumen itu.",
+		"document.unsupported": "Maaf, saya tidak bisa membaca %s. Silakan kirim dokumen PDF atau Word (.docx).",
+		"document.too_large":   "Maaf, %s terlalu besar. Silakan kirim file yang lebih kecil.",
+		"document.too_long":    "Maaf, teks dalam %s terlalu panjang. Silakan kirim dokumen yang lebih pendek atau hanya halaman yang diperlukan.",
+		"document.unreadable":  "Maaf, saya tidak menemukan teks di %s. Dokumen hasil pindaian tidak didukung, jadi silakan kirim dokumen dengan teks yang bisa dipilih.",
+		"document.failed":      "Maaf, saya tidak bisa membuka %s. Silakan coba kirim lagi.",
+
+		"command.voice":        "Pilih balasan suara atau teks, mis. /suara on",
+		"speech.disabled":      "Maaf, saya hanya bisa membalas dengan teks.",
+		"speech.usage":         "Kirim /suara on untuk balasan suara, /suara off untuk balasan teks, atau /suara auto untuk balasan suara atas pesan suara Anda.",
+		"speech.current.text":  "Saya membalas dengan teks. Kirim /suara on untuk balasan suara.",
+		"speech.current.voice": "Saya membalas dengan pesan suara. Kirim /suara off untuk balasan teks.",
+		"speech.current.auto":  "Saya membalas pesan suara dengan pesan suara dan teks dengan teks.",
+		"speech.changed.text":  "Baik, saya akan membalas dengan teks.",
+		"speech.changed.voice": "Baik, saya akan membalas dengan pesan suara.",
+		"speech.changed.auto":  "Baik, saya akan membalas pesan suara Anda dengan pesan suara dan teks dengan teks.",
+
+		"reply.failed": "Maaf, saya sedang kesulitan memproses pesan Anda saat ini.",
+		"lang.auto":    "Baik, saya akan membalas dalam bahasa yang Anda gunakan.",
+	},
+	Javanese: {
+		"command.help":     "Tampilaken daftar prentah punika",
+		"command.reset":    "Supekaken obrolan kita, batalaken formulir utawi panyuwunan petugas, lan wiwit malih saking awal",
+		"command.lang":     "Gantos basa wangsulan, tuladhanipun /lang jv",
+		"command.human":    "Nyuwun ngendikan kaliyan petugas",
+		"command.timezone": "Atur zona wekdal kangge pangeling, tuladhanipun /zonawaktu WIB",
+		"command.unknown":  "Kula mboten mangertos prentah %s. Kintunaken /bantuan kangge ningali menapa ingkang saged kula tindakaken.",
+		"command.failed":   "Nyuwun pangapunten, wonten kalepatan. Mangga dipuncoba malih.",
+		"help.header":      "Punika prentah ingkang saged panjenengan kintunaken:",
+		"help.footer":      "Pesen sanesipun badhe dipunwangsuli dening asisten.",
+		"reset.done":       "Sampun! Obrolan kita sampun kula supekaken, formulir utawi panyuwunan petugas ugi sampun kula batalaken. Wonten ingkang saged kula biyantu?",
+		"lang.current":     "Kula mangsuli ing basa %s. Basa ingkang wonten: %s. Kintunaken /lang lajeng kode basa kangge nggantos, utawi /lang auto supados kula ndherek basa ingkang panjenengan ginakaken.",
+		"lang.changed":     "Nggih, wiwit sakmenika kula badhe mangsuli ing basa Jawi.",
+		"lang.unsupported": "Nyuwun pangapunten, kula dereng saged basa %q. Basa ingkang wonten: %s.",
+		"timezone.current": "Zona wekdal panjenengan %s. Kintunaken /zonawaktu lajeng nami zona kados Asia/Jakarta utawi WIB kangge nggantos.",
+		"timezone.changed": "Nggih, zona wekdal panjenengan sakmenika %s. Ing ngrika sakmenika jam %s.",
+		"timezone.unknown": "Nyuwun pangapunten, kula mboten mangertos zona wekdal %q. Cobi nami kados Asia/Jakarta, utawi WIB, WITA, WIT.",
+		"human.requested":  "Matur nuwun, kula sampun nyuwun tim kula supados ngubungi panjenengan ing mriki.",
+		"handoff.started":  "Nyuwun pangapunten, kula kangelan mbiyantu bab punika. Kula sampun nyuwun tim kula supados nglajengaken.",
+		"handoff.ended":    "Panjenengan sampun kahubung malih kaliyan asisten. Kintunaken /human menawi mbetahaken tim kula.",
+		"handoff.closed":   "Obrolan punika sampun dipuntutup. Kintunaken pesen kapan kemawon menawi panjenengan mbetahaken pitulungan malih.",
+		"handoff.queued":   "Nyuwun pangapunten, kula kangelan mbiyantu bab punika. Tim kula saweg mboten tugas, pramila kula sampun nyuwun supados obrolan punika dipunlajengaken menawi tim sampun wangsul jam %s.",
+		"handoff.dequeued": "Tim kula sampun wangsul lan badhe enggal nglajengaken obrolan punika kaliyan panjenengan.",
+		"human.queued":     "Tim kula saweg mboten tugas. Panjenengan sampun mlebet antrean lan badhe dipunhubungi menawi tim sampun wangsul jam %s. Sauntawis punika, kula tetep siyaga mbiyantu.",
+		"hours.closed":     "Tim kula saweg mboten tugas lan badhe wangsul jam %s. Sauntawis punika kula tetep saged mbiyantu kathah pitakenan panjenengan.",
+		"consent.stopped":  "Panjenengan sampun mandheg langganan lan mboten badhe nampi pesen malih saking kula. Wangsuli %s kangge langganan malih.",
+		"consent.started":  "Panjenengan sampun langganan malih. Wangsuli %s kapan kemawon kangge mandheg nampi pesen.",
+
+		"command.cancel":      "Mandheg ngisi formulir ingkang saweg lumampah",
+		"flow.cancelled":      "Nggih, formulir sampun kula mandhegaken. Wangsulan panjenengan mboten dipunkintun.",
+		"flow.none":           "Mboten wonten formulir ingkang saweg dipunisi.",
+		"flow.completed":      "Matur nuwun, wangsulan panjenengan sampun kula tampi.",
+		"flow.invalid.text":   "Mangga ketik wangsulan panjenengan.",
+		"flow.invalid.number": "Punika kados-kados sanes angka. Mangga kintunaken angka kemawon, tuladhanipun 12.",
+		"flow.invalid.phone":  "Punika kados-kados sanes nomer telpon. Mangga kintunaken namung angka, tuladhanipun 0812 3456 7890.",
+		"flow.invalid.email":  "Punika kados-kados sanes alamat email. Mangga kintunaken kados nama@conto.com.",
+		"flow.invalid.date":   "Kula mboten saged maos tanggal punika. Mangga kintunaken kados tanggal/wulan/taun, tuladhanipun 17/08/2024.",
+		"flow.invalid.choice": "Mangga pilih salah satunggaling pilihan.",
+
+		"voice.heard":       "Kula mireng: %s",
+		"voice.unsupported": "Nyuwun pangapunten, kula mboten saged mirengaken pesen swanten. Mangga ketik pesen panjenengan.",
+		"voice.failed":      "Nyuwun pangapunten, kula mboten saged mangertosi pesen swanten punika. Mangga dipuncoba malih utawi ketik pesen panjenengan.",
+		"voice.too_long":    "Nyuwun pangapunten, pesen swanten punika kepanjangen. Mangga kintunaken ingkang langkung cekak utawi ketik pesen panjenengan.",
+
+		"image.failed":    "Nyuwun pangapunten, kula mboten saged mbikak gambar punika. Mangga dipuncoba kintun malih.",
+		"image.too_large": "Nyuwun pangapunten, gambar punika kagengen. Mangga kintunaken ingkang langkung alit.",
+
+		"document.received":    "Kula sampun maos %s. Mangga takenaken menapa kemawon babagan dokumen punika.",
+		"document.unsupported": "Nyuwun pangapunten, kula mboten saged maos %s. Mangga kintunaken dokumen PDF utawi Word (.docx).",
+		"document.too_large":   "Nyuwun pangapunten, %s kagengen. Mangga kintunaken file ingkang langkung alit.",
+		"document.too_long":    "Nyuwun pangapunten, seratan ing %s kepanjangen. Mangga kintunaken dokumen ingkang langkung cekak utawi namung kaca ingkang dipunbetahaken.",
+		"document.unreadable":  "Nyuwun pangapunten, kula mboten manggihaken seratan ing %s. Dokumen asil pindaian dereng saged kula waos, pramila mangga kintunaken dokumen ingkang seratanipun saged dipunpilih.",
+		"document.failed":      "Nyuwun pangapunten, kula mboten saged mbikak %s. Mangga dipuncoba kintun malih.",
+
+		"command.voice":        "Pilih wangsulan swanten utawi seratan, tuladhanipun /suara on",
+		"speech.disabled":      "Nyuwun pangapunten, kula namung saged mangsuli mawi seratan.",
+		"speech.usage":         "Kintunaken /suara on kangge wangsulan swanten, /suara off kangge wangsulan seratan, utawi /suara auto kangge wangsulan swanten tumrap pesen swanten panjenengan.",
+		"speech.current.text":  "Kula mangsuli mawi seratan. Kintunaken /suara on kangge wangsulan swanten.",
+		"speech.current.voice": "Kula mangsuli mawi pesen swanten. Kintunaken /suara off kangge wangsulan seratan.",
+		"speech.current.auto":  "Kula mangsuli pesen swanten mawi pesen swanten lan seratan mawi seratan.",
+		"speech.changed.text":  "Nggih, kula badhe mangsuli mawi seratan.",
+		"speech.changed.voice": "Nggih, kula badhe mangsuli mawi pesen swanten.",
+		"speech.changed.auto":  "Nggih, kula badhe mangsuli pesen swanten panjenengan mawi pesen swanten lan seratan mawi seratan.",
+
+		"reply.failed": "Nyuwun pangapunten, kula saweg kangelan ngolah pesen panjenengan. Mangga dipuncoba malih sekedhap malih.",
+		"lang.auto":    "Nggih, kula badhe mangsuli ing basa ingkang panjenengan ginakaken.",
+	},
+}
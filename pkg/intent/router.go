@@ -0,0 +1,325 @@
+package intent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Match types
+const (
+	MatchExact   = "exact"
+	MatchKeyword = "keyword"
+	MatchRegex   = "regex"
+	MatchFuzzy   = "fuzzy"
+)
+
+// Reply types
+const (
+	ReplyText     = "text"
+	ReplyButtons  = "buttons"
+	ReplyTemplate = "template"
+	ReplyFlow     = "flow"
+)
+
+// defaultFuzzyThreshold is the similarity required by fuzzy rules that don't set one
+const defaultFuzzyThreshold = 0.8
+
+// Rule maps matching messages to a canned reply
+type Rule struct {
+	Name string `json:"name"`
+	// Match is one of exact, keyword, regex or fuzzy
+	Match string `json:"match"`
+	// Patterns are alternatives; the rule matches when any of them does
+	Patterns []string `json:"patterns"`
+	// Threshold is the similarity between 0 and 1 required by fuzzy rules
+	Threshold float64 `json:"threshold,omitempty"`
+	Reply     Reply   `json:"reply"`
+}
+
+// Reply is the canned answer of a rule
+type Reply struct {
+	// Type is one of text, buttons, template or flow
+	Type     string                       `json:"type"`
+	Text     string                       `json:"text,omitempty"`
+	Buttons  []models.WhatsAppButtonReply `json:"buttons,omitempty"`
+	Template *TemplateReply               `json:"template,omitempty"`
+	// Flow names the form started by a flow reply
+	Flow string `json:"flow,omitempty"`
+}
+
+// TemplateReply names a pre-approved WhatsApp template and its body parameters
+type TemplateReply struct {
+	Name       string   `json:"name"`
+	Language   string   `json:"language"`
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+// rulesFile is the layout of the rules file
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// compiledRule is a rule prepared for matching
+type compiledRule struct {
+	Rule
+	normalized []string
+	regexps    []*regexp.Regexp
+}
+
+// Router matches messages against rules loaded from a file, in file order
+type Router struct {
+	mu      sync.RWMutex
+	path    string
+	modTime time.Time
+	rules   []compiledRule
+}
+
+// NewRouter loads the rules file at path
+func NewRouter(path string) (*Router, error) {
+	r := &Router{path: path}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload re-reads the rules file; the current rules are kept if it is invalid
+func (r *Router) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	rules := make([]compiledRule, 0, len(file.Rules))
+	for _, rule := range file.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, compiled)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = rules
+	r.modTime = info.ModTime()
+	return nil
+}
+
+// Watch reloads the rules whenever the file changes until the context is cancelled.
+// It returns at once when the interval isn't positive.
+func (r *Router) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				continue
+			}
+
+			r.mu.RLock()
+			changed := !info.ModTime().Equal(r.modTime)
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err := r.Reload(); err != nil {
+				log.Printf("Failed to reload intent rules, keeping the previous ones: %v", err)
+				continue
+			}
+			log.Printf("Reloaded intent rules from %s", r.path)
+		}
+	}
+}
+
+// Match returns the first rule matching the message, if any
+func (r *Router) Match(message models.Message) (*Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	text := normalize(message.Text)
+	for i := range r.rules {
+		if r.rules[i].matches(text, message) {
+			rule := r.rules[i].Rule
+			return &rule, true
+		}
+	}
+	return nil, false
+}
+
+// compileRule validates a rule and prepares its patterns
+func compileRule(rule Rule) (compiledRule, error) {
+	compiled := compiledRule{Rule: rule}
+	if len(rule.Patterns) == 0 {
+		return compiled, fmt.Errorf("rule %q has no patterns", rule.Name)
+	}
+
+	switch rule.Match {
+	case MatchExact, MatchKeyword, MatchFuzzy:
+		for _, pattern := range rule.Patterns {
+			compiled.normalized = append(compiled.normalized, normalize(pattern))
+		}
+	case MatchRegex:
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile("(?i)" + pattern)
+			if err != nil {
+				return compiled, fmt.Errorf("rule %q has an invalid regex: %w", rule.Name, err)
+			}
+			compiled.regexps = append(compiled.regexps, re)
+		}
+	default:
+		return compiled, fmt.Errorf("rule %q has unknown match type %q", rule.Name, rule.Match)
+	}
+	if compiled.Threshold == 0 {
+		compiled.Threshold = defaultFuzzyThreshold
+	}
+
+	switch rule.Reply.Type {
+	case ReplyText:
+		if rule.Reply.Text == "" {
+			return compiled, fmt.Errorf("rule %q has an empty text reply", rule.Name)
+		}
+	case ReplyButtons:
+		if rule.Reply.Text == "" || len(rule.Reply.Buttons) == 0 || len(rule.Reply.Buttons) > 3 {
+			return compiled, fmt.Errorf("rule %q needs reply text and one to three buttons", rule.Name)
+		}
+	case ReplyTemplate:
+		if rule.Reply.Template == nil || rule.Reply.Template.Name == "" {
+			return compiled, fmt.Errorf("rule %q has no template name", rule.Name)
+		}
+	case ReplyFlow:
+		if rule.Reply.Flow == "" {
+			return compiled, fmt.Errorf("rule %q has no flow name", rule.Name)
+		}
+	default:
+		return compiled, fmt.Errorf("rule %q has unknown reply type %q", rule.Name, rule.Reply.Type)
+	}
+
+	return compiled, nil
+}
+
+// matches reports whether the rule matches a normalized message
+func (r *compiledRule) matches(text string, message models.Message) bool {
+	switch r.Match {
+	case MatchExact:
+		for i, pattern := range r.normalized {
+			if text == pattern || (message.ReplyID != "" && message.ReplyID == r.Patterns[i]) {
+				return true
+			}
+		}
+	case MatchKeyword:
+		words := strings.Fields(text)
+		for _, pattern := range r.normalized {
+			if containsAllWords(words, strings.Fields(pattern)) {
+				return true
+			}
+		}
+	case MatchRegex:
+		for _, re := range r.regexps {
+			if re.MatchString(message.Text) {
+				return true
+			}
+		}
+	case MatchFuzzy:
+		for _, pattern := range r.normalized {
+			if similarity(text, pattern) >= r.Threshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalize lowercases text, drops punctuation and collapses whitespace
+func normalize(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// containsAllWords reports whether every keyword appears among the words
+func containsAllWords(words, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+	for _, keyword := range keywords {
+		found := false
+		for _, word := range words {
+			if word == keyword {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// similarity returns 1 minus the normalized Levenshtein distance of two strings
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,163 @@
+package intent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// testRules covers every match type; the order matters for the precedence cases
+const testRules = `{
+  "rules": [
+    {"name": "hours", "match": "keyword", "patterns": ["opening hours", "jam buka"], "reply": {"type": "text", "text": "09:00-21:00"}},
+    {"name": "order", "match": "fuzzy", "patterns": ["where is my order"], "threshold": 0.75, "reply": {"type": "buttons", "text": "Your order?", "buttons": [{"id": "track_order", "title": "Track"}]}},
+    {"name": "track", "match": "exact", "patterns": ["track_order"], "reply": {"type": "text", "text": "Send your order number"}},
+    {"name": "invoice", "match": "regex", "patterns": ["^\\s*invoice\\s*$", "INV-\\d+"], "reply": {"type": "template", "template": {"name": "invoice_help", "language": "en_US"}}},
+    {"name": "refund", "match": "keyword", "patterns": ["refund"], "reply": {"type": "flow", "flow": "return_request"}},
+    {"name": "greeting", "match": "exact", "patterns": ["hi", "halo"], "reply": {"type": "text", "text": "Hello!"}},
+    {"name": "hours_fallback", "match": "exact", "patterns": ["opening hours"], "reply": {"type": "text", "text": "never reached"}}
+  ]
+}`
+
+// newTestRouter writes rules to a temporary file and loads them
+func newTestRouter(t *testing.T, rules string) *Router {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+	router, err := NewRouter(path)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+	return router
+}
+
+func TestRouterMatch(t *testing.T) {
+	router := newTestRouter(t, testRules)
+
+	tests := []struct {
+		name    string
+		message models.Message
+		want    string
+	}{
+		// Keyword: all words of a pattern, in any order, ignoring case and punctuation
+		{name: "keyword", message: models.Message{Text: "What are your opening hours?"}, want: "hours"},
+		{name: "keyword in any order", message: models.Message{Text: "hours opening"}, want: "hours"},
+		{name: "keyword second pattern", message: models.Message{Text: "Jam buka hari ini?"}, want: "hours"},
+		{name: "keyword needs every word", message: models.Message{Text: "opening soon"}, want: ""},
+		{name: "keyword needs whole words", message: models.Message{Text: "refunded"}, want: ""},
+
+		// Fuzzy: edit-distance similarity at or above the threshold
+		{name: "fuzzy exact", message: models.Message{Text: "Where is my order?"}, want: "order"},
+		{name: "fuzzy typo", message: models.Message{Text: "wher is my ordr"}, want: "order"},
+		{name: "fuzzy below threshold", message: models.Message{Text: "where is the shop"}, want: ""},
+
+		// Exact: the whole normalized message or the ID of a tapped button
+		{name: "exact", message: models.Message{Text: "Hi!"}, want: "greeting"},
+		{name: "exact not partial", message: models.Message{Text: "hi there"}, want: ""},
+		{name: "exact button ID", message: models.Message{Text: "Track", ReplyID: "track_order"}, want: "track"},
+
+		// Regex: case-insensitive against the original text
+		{name: "regex", message: models.Message{Text: "  INVOICE "}, want: "invoice"},
+		{name: "regex second pattern", message: models.Message{Text: "status of inv-10023"}, want: "invoice"},
+
+		// Precedence: the first matching rule in file order wins
+		{name: "earlier rule wins", message: models.Message{Text: "opening hours"}, want: "hours"},
+		{name: "earlier rule wins across types", message: models.Message{Text: "refund invoice INV-1"}, want: "invoice"},
+
+		// No match
+		{name: "no match", message: models.Message{Text: "tell me a joke"}, want: ""},
+		{name: "empty message", message: models.Message{}, want: ""},
+		{name: "punctuation only", message: models.Message{Text: "?!"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := router.Match(tt.message)
+			got := ""
+			if ok {
+				got = rule.Name
+			}
+			if got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.message.Text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterMatchIsDeterministic(t *testing.T) {
+	router := newTestRouter(t, testRules)
+	message := models.Message{Text: "where is my order, refund please"}
+
+	first, ok := router.Match(message)
+	if !ok {
+		t.Fatal("Match() found no rule")
+	}
+	for i := 0; i < 100; i++ {
+		rule, _ := router.Match(message)
+		if rule.Name != first.Name {
+			t.Fatalf("Match() = %q on run %d, want %q", rule.Name, i, first.Name)
+		}
+	}
+}
+
+func TestCompileRuleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{name: "no patterns", rule: Rule{Name: "r", Match: MatchExact, Reply: Reply{Type: ReplyText, Text: "x"}}},
+		{name: "unknown match type", rule: Rule{Name: "r", Match: "prefix", Patterns: []string{"a"}, Reply: Reply{Type: ReplyText, Text: "x"}}},
+		{name: "invalid regex", rule: Rule{Name: "r", Match: MatchRegex, Patterns: []string{"("}, Reply: Reply{Type: ReplyText, Text: "x"}}},
+		{name: "empty text reply", rule: Rule{Name: "r", Match: MatchExact, Patterns: []string{"a"}, Reply: Reply{Type: ReplyText}}},
+		{name: "too many buttons", rule: Rule{Name: "r", Match: MatchExact, Patterns: []string{"a"}, Reply: Reply{Type: ReplyButtons, Text: "x", Buttons: make([]models.WhatsAppButtonReply, 4)}}},
+		{name: "template without name", rule: Rule{Name: "r", Match: MatchExact, Patterns: []string{"a"}, Reply: Reply{Type: ReplyTemplate}}},
+		{name: "flow without name", rule: Rule{Name: "r", Match: MatchExact, Patterns: []string{"a"}, Reply: Reply{Type: ReplyFlow}}},
+		{name: "unknown reply type", rule: Rule{Name: "r", Match: MatchExact, Patterns: []string{"a"}, Reply: Reply{Type: "image"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := compileRule(tt.rule); err == nil {
+				t.Error("compileRule() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestReloadKeepsRulesWhenInvalid(t *testing.T) {
+	router := newTestRouter(t, testRules)
+
+	if err := os.WriteFile(router.path, []byte(`{"rules": [{"name": "broken", "match": "regex", "patterns": ["("]}]}`), 0o644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+	if err := router.Reload(); err == nil {
+		t.Fatal("Reload() error = nil, want an error")
+	}
+	if rule, ok := router.Match(models.Message{Text: "hi"}); !ok || rule.Name != "greeting" {
+		t.Errorf("Match() after a failed reload = %v, %v, want the previous rules", rule, ok)
+	}
+}
+
+func TestWatchWithoutInterval(t *testing.T) {
+	router := newTestRouter(t, testRules)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		done := make(chan struct{})
+		go func() {
+			router.Watch(context.Background(), interval)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Watch(%s) did not return", interval)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package jsonfile keeps values in JSON files, the format shared by the file-backed stores
+package jsonfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Write encodes v as JSON and replaces the file at path. The data goes to a temporary file
+// that is then renamed, so a crash never leaves a partial file.
+func Write(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Read decodes the JSON file at path into v; a missing file gives an error matching os.ErrNotExist
+func Read(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// Dir is a directory holding one JSON file per key. It is safe for concurrent use.
+type Dir struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewDir opens a directory of JSON files, creating it if needed
+func NewDir(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	return &Dir{path: path}, nil
+}
+
+// Path returns the file path of a key
+func (d *Dir) Path(key string) string {
+	return filepath.Join(d.path, filepath.Base(key)+".json")
+}
+
+// Get decodes the file of a key into v, reporting false when there is none
+func (d *Dir) Get(key string, v interface{}) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := Read(d.Path(key), v)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Put writes the file of a key atomically
+func (d *Dir) Put(key string, v interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return Write(d.Path(key), v)
+}
+
+// Delete removes the file of a key; a missing file is not an error
+func (d *Dir) Delete(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.Remove(d.Path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List decodes every file in the directory
+func List[T any](d *Dir) ([]*T, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(d.path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*T, 0, len(paths))
+	for _, path := range paths {
+		v := new(T)
+		if err := Read(path, v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
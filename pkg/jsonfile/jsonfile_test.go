@@ -0,0 +1,73 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+type record struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+func TestDir(t *testing.T) {
+	dir, err := NewDir(filepath.Join(t.TempDir(), "records"))
+	if err != nil {
+		t.Fatalf("NewDir() error = %v", err)
+	}
+
+	var got record
+	if found, err := dir.Get("a", &got); found || err != nil {
+		t.Fatalf("Get() of a missing key = %v, %v, want false, nil", found, err)
+	}
+
+	for _, r := range []record{{ID: "a", Text: "first"}, {ID: "b", Text: "second"}, {ID: "a", Text: "replaced"}} {
+		if err := dir.Put(r.ID, r); err != nil {
+			t.Fatalf("Put(%s) error = %v", r.ID, err)
+		}
+	}
+	if found, err := dir.Get("a", &got); !found || err != nil || got.Text != "replaced" {
+		t.Errorf("Get(a) = %+v, %v, %v, want the replaced record", got, found, err)
+	}
+
+	// Keys can't escape the directory
+	if path := dir.Path("../x"); filepath.Dir(path) != dir.path {
+		t.Errorf("Path(../x) = %s, want a file in %s", path, dir.path)
+	}
+
+	// Temporary files left by a crash are not listed
+	if err := os.WriteFile(dir.Path("c")+".tmp", []byte("{"), 0o644); err != nil {
+		t.Fatalf("failed to write temporary file: %v", err)
+	}
+	records, err := List[record](dir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	if len(records) != 2 || records[0].Text != "replaced" || records[1].Text != "second" {
+		t.Errorf("List() = %+v, want records a and b", records)
+	}
+
+	if err := dir.Delete("a"); err != nil {
+		t.Fatalf("Delete(a) error = %v", err)
+	}
+	if err := dir.Delete("a"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v, want nil", err)
+	}
+	if found, _ := dir.Get("a", &got); found {
+		t.Error("Get(a) after Delete() found the record")
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	var r record
+	if err := Read(path, &r); err == nil {
+		t.Error("Read() of invalid JSON error = nil, want an error")
+	}
+}
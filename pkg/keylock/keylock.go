@@ -0,0 +1,46 @@
+// Package keylock provides mutexes keyed by a string, such as a user ID
+package keylock
+
+import "sync"
+
+// entry is the mutex of a key and the number of callers holding or waiting for it
+type entry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Locks serializes work per key. A key's mutex is dropped once nobody holds or waits for it,
+// so the map only grows with the keys in use.
+type Locks struct {
+	mu    sync.Mutex
+	locks map[string]*entry
+}
+
+// New creates an empty set of locks
+func New() *Locks {
+	return &Locks{locks: make(map[string]*entry)}
+}
+
+// Lock locks a key until the returned function is called
+func (l *Locks) Lock(key string) func() {
+	l.mu.Lock()
+	e, ok := l.locks[key]
+	if !ok {
+		e = &entry{}
+		l.locks[key] = e
+	}
+	e.refs++
+	l.mu.Unlock()
+
+	e.mu.Lock()
+	return func() {
+		e.mu.Unlock()
+
+		l.mu.Lock()
+		e.refs--
+		if e.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
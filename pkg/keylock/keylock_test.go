@@ -0,0 +1,52 @@
+package keylock
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockSerializesKey(t *testing.T) {
+	locks := New()
+
+	var wg sync.WaitGroup
+	counts := map[string]*int{"a": new(int), "b": new(int)}
+	for i := 0; i < 100; i++ {
+		for _, key := range []string{"a", "b"} {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				unlock := locks.Lock(key)
+				defer unlock()
+				// Only the holder of the key's lock touches its count
+				n := *counts[key]
+				*counts[key] = n + 1
+			}(key)
+		}
+	}
+	wg.Wait()
+
+	if *counts["a"] != 100 || *counts["b"] != 100 {
+		t.Errorf("counts = %d, %d, want 100 for each key", *counts["a"], *counts["b"])
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks left after every unlock, want 0", len(locks.locks))
+	}
+}
+
+func TestLockIsDroppedOnLastUnlock(t *testing.T) {
+	locks := New()
+
+	unlock := locks.Lock("a")
+	waiting := make(chan func())
+	go func() { waiting <- locks.Lock("a") }()
+
+	unlock()
+	second := <-waiting
+	if len(locks.locks) != 1 {
+		t.Fatalf("%d locks while the key is held, want 1", len(locks.locks))
+	}
+	second()
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks after the last unlock, want 0", len(locks.locks))
+	}
+}
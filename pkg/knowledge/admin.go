@@ -0,0 +1,197 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Errors returned by the document management methods
+var (
+	ErrNoSourceDir       = errors.New("KNOWLEDGE_DIR must be set to manage documents")
+	ErrInvalidDocumentID = errors.New("invalid document ID")
+	ErrDocumentNotFound  = errors.New("document not found")
+	ErrReindexRunning    = errors.New("reindexing is already running")
+)
+
+// Documents lists the indexed documents
+func (b *Base) Documents(ctx context.Context) ([]models.KnowledgeDocument, error) {
+	return b.index.Documents(ctx)
+}
+
+// Document returns an indexed document
+func (b *Base) Document(ctx context.Context, id string) (*models.KnowledgeDocument, error) {
+	doc, err := b.index.Document(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrDocumentNotFound
+	}
+	return doc, nil
+}
+
+// Upload stores a new or updated document in the source directory and indexes it.
+// The document ID is its file name.
+func (b *Base) Upload(ctx context.Context, name string, data []byte) (*models.KnowledgeDocument, error) {
+	name = filepath.Base(filepath.Clean(name))
+	if name == "." || name == string(filepath.Separator) || strings.HasPrefix(name, ".") {
+		return nil, ErrInvalidDocumentID
+	}
+	return b.Update(ctx, name, data)
+}
+
+// Update replaces the content of a document in the source directory and re-indexes it
+func (b *Base) Update(ctx context.Context, id string, data []byte) (*models.KnowledgeDocument, error) {
+	filePath, err := b.sourcePath(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := formatOf(id); err != nil {
+		return nil, err
+	}
+
+	b.sourceMu.Lock()
+	defer b.sourceMu.Unlock()
+
+	// Index first so a document that can't be parsed never replaces a good one
+	doc, err := b.ingest(ctx, id, path.Base(id), data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create document directory: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+	return doc, nil
+}
+
+// Delete removes a document from the source directory and the index
+func (b *Base) Delete(ctx context.Context, id string) error {
+	filePath, err := b.sourcePath(id)
+	if err != nil {
+		return err
+	}
+
+	b.sourceMu.Lock()
+	defer b.sourceMu.Unlock()
+
+	doc, err := b.index.Document(ctx, id)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return ErrDocumentNotFound
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return b.index.Delete(ctx, id)
+}
+
+// StartReindex re-indexes the source directory in the background. Unchanged documents are
+// skipped unless force is set, and documents whose files were removed are dropped.
+func (b *Base) StartReindex(force bool) error {
+	if b.config.KnowledgeDir == "" {
+		return ErrNoSourceDir
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.reindex.Running {
+		return ErrReindexRunning
+	}
+	b.reindex = models.ReindexStatus{
+		Running:   true,
+		Force:     force,
+		StartedAt: time.Now(),
+	}
+
+	go b.runReindex(force)
+	return nil
+}
+
+// ReindexStatus reports the state of the last reindexing run
+func (b *Base) ReindexStatus() models.ReindexStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.reindex
+}
+
+// runReindex performs a reindexing run and records its outcome
+func (b *Base) runReindex(force bool) {
+	ctx := context.Background()
+	err := b.syncSourceDir(ctx, force)
+	if err != nil {
+		log.Printf("Knowledge reindexing failed: %v", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reindex.Running = false
+	b.reindex.FinishedAt = time.Now()
+	if err != nil {
+		b.reindex.Error = err.Error()
+	}
+}
+
+// syncSourceDir ingests the source directory and drops documents no longer in it
+func (b *Base) syncSourceDir(ctx context.Context, force bool) error {
+	found, err := b.ingestDir(ctx, b.config.KnowledgeDir, force)
+	if err != nil {
+		return err
+	}
+
+	// Uploads index a document before writing its file, so prune under their lock and
+	// keep documents whose files appeared since the walk
+	b.sourceMu.Lock()
+	defer b.sourceMu.Unlock()
+
+	docs, err := b.index.Documents(ctx)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if !found[doc.ID] {
+			if filePath, err := b.sourcePath(doc.ID); err == nil {
+				if _, err := os.Stat(filePath); err == nil {
+					continue
+				}
+			}
+			if err := b.index.Delete(ctx, doc.ID); err != nil {
+				return err
+			}
+			log.Printf("Removed %s from the knowledge index", doc.ID)
+		}
+	}
+	return nil
+}
+
+// sourcePath resolves a document ID to its file in the source directory
+func (b *Base) sourcePath(id string) (string, error) {
+	if b.config.KnowledgeDir == "" {
+		return "", ErrNoSourceDir
+	}
+
+	// IDs are slash-separated paths relative to the source directory
+	clean := path.Clean(id)
+	if id == "" || clean != id || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", ErrInvalidDocumentID
+	}
+	return filepath.Join(b.config.KnowledgeDir, filepath.FromSlash(clean)), nil
+}
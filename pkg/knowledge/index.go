@@ -0,0 +1,184 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// EmbeddedChunk is a knowledge chunk with its embedding vector
+type EmbeddedChunk struct {
+	models.KnowledgeChunk
+	Vector []float32 `json:"vector"`
+}
+
+// Index stores embedded chunks and searches them by similarity
+type Index interface {
+	// Documents lists the indexed documents
+	Documents(ctx context.Context) ([]models.KnowledgeDocument, error)
+	// Document returns an indexed document, or nil if it is not indexed
+	Document(ctx context.Context, id string) (*models.KnowledgeDocument, error)
+	// Put replaces a document and all of its chunks
+	Put(ctx context.Context, doc models.KnowledgeDocument, chunks []EmbeddedChunk) error
+	// Delete removes a document and its chunks
+	Delete(ctx context.Context, id string) error
+	// Search returns the k chunks most similar to the vector
+	Search(ctx context.Context, vector []float32, k int) ([]models.RetrievedChunk, error)
+}
+
+// FileIndex is an embedded index held in memory and persisted to a JSON file
+type FileIndex struct {
+	mu        sync.RWMutex
+	path      string
+	documents map[string]models.KnowledgeDocument
+	chunks    map[string][]EmbeddedChunk
+}
+
+// fileIndexData is the on-disk layout of a FileIndex
+type fileIndexData struct {
+	Documents []models.KnowledgeDocument `json:"documents"`
+	Chunks    []EmbeddedChunk            `json:"chunks"`
+}
+
+// NewFileIndex opens the index stored at path, creating it if needed.
+// An empty path keeps the index in memory only.
+func NewFileIndex(path string) (*FileIndex, error) {
+	idx := &FileIndex{
+		path:      path,
+		documents: make(map[string]models.KnowledgeDocument),
+		chunks:    make(map[string][]EmbeddedChunk),
+	}
+	if path == "" {
+		return idx, nil
+	}
+
+	var stored fileIndexData
+	err := jsonfile.Read(path, &stored)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read knowledge index: %w", err)
+	}
+	for _, doc := range stored.Documents {
+		idx.documents[doc.ID] = doc
+	}
+	for _, chunk := range stored.Chunks {
+		idx.chunks[chunk.DocumentID] = append(idx.chunks[chunk.DocumentID], chunk)
+	}
+	return idx, nil
+}
+
+// Documents lists the indexed documents sorted by ID
+func (idx *FileIndex) Documents(ctx context.Context) ([]models.KnowledgeDocument, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	docs := make([]models.KnowledgeDocument, 0, len(idx.documents))
+	for _, doc := range idx.documents {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs, nil
+}
+
+// Document returns an indexed document, or nil if it is not indexed
+func (idx *FileIndex) Document(ctx context.Context, id string) (*models.KnowledgeDocument, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	doc, ok := idx.documents[id]
+	if !ok {
+		return nil, nil
+	}
+	return &doc, nil
+}
+
+// Put replaces a document and all of its chunks
+func (idx *FileIndex) Put(ctx context.Context, doc models.KnowledgeDocument, chunks []EmbeddedChunk) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.documents[doc.ID] = doc
+	idx.chunks[doc.ID] = chunks
+	return idx.persist()
+}
+
+// Delete removes a document and its chunks
+func (idx *FileIndex) Delete(ctx context.Context, id string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	delete(idx.documents, id)
+	delete(idx.chunks, id)
+	return idx.persist()
+}
+
+// Search returns the k chunks with the highest cosine similarity to the vector
+func (idx *FileIndex) Search(ctx context.Context, vector []float32, k int) ([]models.RetrievedChunk, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var results []models.RetrievedChunk
+	for _, chunks := range idx.chunks {
+		for _, chunk := range chunks {
+			results = append(results, models.RetrievedChunk{
+				KnowledgeChunk: chunk.KnowledgeChunk,
+				Score:          cosineSimilarity(vector, chunk.Vector),
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// persist writes the index to disk atomically; the caller must hold the lock
+func (idx *FileIndex) persist() error {
+	if idx.path == "" {
+		return nil
+	}
+
+	stored := fileIndexData{}
+	for _, doc := range idx.documents {
+		stored.Documents = append(stored.Documents, doc)
+	}
+	for _, chunks := range idx.chunks {
+		stored.Chunks = append(stored.Chunks, chunks...)
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create knowledge index directory: %w", err)
+	}
+	if err := jsonfile.Write(idx.path, stored); err != nil {
+		return fmt.Errorf("failed to write knowledge index: %w", err)
+	}
+	return nil
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,232 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/document"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// embeddingBatchSize is the number of chunks embedded per request
+const embeddingBatchSize = 16
+
+// Embedder turns texts into embedding vectors; llm.NewEmbedder creates one for the configured provider
+type Embedder interface {
+	CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Base is a knowledge base of documents searchable by meaning
+type Base struct {
+	config   *config.Config
+	index    Index
+	embedder Embedder
+
+	// Serializes changes to the source directory with their indexing
+	sourceMu sync.Mutex
+
+	// Reindexing state
+	mu      sync.Mutex
+	reindex models.ReindexStatus
+}
+
+// NewBase opens the knowledge base configured by cfg
+func NewBase(cfg *config.Config, embedder Embedder) (*Base, error) {
+	index, err := NewFileIndex(cfg.KnowledgeIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Base{
+		config:   cfg,
+		index:    index,
+		embedder: embedder,
+	}, nil
+}
+
+// Ingest chunks, embeds and indexes a document, replacing any previous version with the same ID.
+// Documents whose content is unchanged are not embedded again.
+func (b *Base) Ingest(ctx context.Context, id, name string, data []byte) (*models.KnowledgeDocument, error) {
+	return b.ingest(ctx, id, name, data, false)
+}
+
+// ingest indexes a document; force re-embeds it even when its content is unchanged
+func (b *Base) ingest(ctx context.Context, id, name string, data []byte, force bool) (*models.KnowledgeDocument, error) {
+	format, err := formatOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip documents that are already indexed with the same content
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if !force {
+		if existing, err := b.index.Document(ctx, id); err == nil && existing != nil && existing.Hash == hash {
+			return existing, nil
+		}
+	}
+
+	sections, err := loadSections(name, format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chunk each section, keeping its title with every chunk
+	var chunks []EmbeddedChunk
+	for _, sec := range sections {
+		source := name
+		if sec.Title != "" {
+			source = name + " > " + sec.Title
+		}
+		for _, text := range document.Chunk(sec.Text, b.config.KnowledgeChunkSize, b.config.KnowledgeChunkOverlap) {
+			if sec.Title != "" && !strings.HasPrefix(text, "Q: ") {
+				text = sec.Title + "\n\n" + text
+			}
+			chunks = append(chunks, EmbeddedChunk{
+				KnowledgeChunk: models.KnowledgeChunk{
+					ID:         fmt.Sprintf("%s#%d", id, len(chunks)),
+					DocumentID: id,
+					Source:     source,
+					Text:       text,
+				},
+			})
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%s contains no text", name)
+	}
+
+	if err := b.embedChunks(ctx, chunks); err != nil {
+		return nil, err
+	}
+
+	doc := models.KnowledgeDocument{
+		ID:         id,
+		Name:       name,
+		Format:     format,
+		Hash:       hash,
+		ChunkCount: len(chunks),
+		UpdatedAt:  time.Now(),
+	}
+	if err := b.index.Put(ctx, doc, chunks); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// IngestDir ingests every supported file under a directory, using relative paths as IDs
+func (b *Base) IngestDir(ctx context.Context, dir string) error {
+	_, err := b.ingestDir(ctx, dir, false)
+	return err
+}
+
+// ingestDir ingests a directory and returns the IDs of the documents found in it
+func (b *Base) ingestDir(ctx context.Context, dir string, force bool) (map[string]bool, error) {
+	found := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if _, err := formatOf(path); err != nil {
+			return nil
+		}
+
+		id, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		id = filepath.ToSlash(id)
+		found[id] = true
+
+		// Read and index under the lock so an upload of the same file can't be overwritten by stale content
+		b.sourceMu.Lock()
+		defer b.sourceMu.Unlock()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		doc, err := b.ingest(ctx, id, filepath.Base(path), data, force)
+		if err != nil {
+			// One bad file shouldn't keep the rest of the knowledge base out
+			log.Printf("Failed to ingest %s: %v", path, err)
+			return nil
+		}
+		log.Printf("Indexed %s (%d chunks)", doc.ID, doc.ChunkCount)
+		return nil
+	})
+	return found, err
+}
+
+// Retrieve returns the passages relevant enough to a query to be used in answers, best first
+func (b *Base) Retrieve(ctx context.Context, query string, k int) ([]models.RetrievedChunk, error) {
+	results, err := b.Search(ctx, query, k)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop passages too dissimilar to be useful
+	relevant := results[:0]
+	for _, result := range results {
+		if result.Score >= b.config.KnowledgeMinScore {
+			relevant = append(relevant, result)
+		}
+	}
+	return relevant, nil
+}
+
+// Search returns the k passages most similar to a query regardless of the minimum score
+func (b *Base) Search(ctx context.Context, query string, k int) ([]models.RetrievedChunk, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	if k <= 0 {
+		k = b.config.KnowledgeTopK
+	}
+
+	vectors, err := b.embedder.CreateEmbedding(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) == 0 {
+		return nil, errors.New("no embedding returned for query")
+	}
+
+	return b.index.Search(ctx, vectors[0], k)
+}
+
+// embedChunks fills in the vectors of the chunks in batches
+func (b *Base) embedChunks(ctx context.Context, chunks []EmbeddedChunk) error {
+	for start := 0; start < len(chunks); start += embeddingBatchSize {
+		end := start + embeddingBatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+
+		texts := make([]string, 0, end-start)
+		for _, chunk := range chunks[start:end] {
+			texts = append(texts, chunk.Text)
+		}
+		vectors, err := b.embedder.CreateEmbedding(ctx, texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		if len(vectors) != len(texts) {
+			return fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+		}
+		for i, vector := range vectors {
+			chunks[start+i].Vector = vector
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package knowledge
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/document"
+)
+
+// section is a titled piece of a document that is chunked independently
+type section struct {
+	Title string
+	Text  string
+}
+
+// Supported formats
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+	FormatPDF      = "pdf"
+	FormatDOCX     = "docx"
+	FormatFAQ      = "faq"
+)
+
+// formatOf returns the knowledge format of a file name
+func formatOf(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return FormatMarkdown, nil
+	case ".txt":
+		return FormatText, nil
+	case ".pdf":
+		return FormatPDF, nil
+	case ".docx":
+		return FormatDOCX, nil
+	case ".csv":
+		return FormatFAQ, nil
+	default:
+		return "", fmt.Errorf("%w: %s", document.ErrUnsupportedFormat, filepath.Base(name))
+	}
+}
+
+// loadSections parses a document into sections according to its format
+func loadSections(name, format string, data []byte) ([]section, error) {
+	switch format {
+	case FormatFAQ:
+		return loadFAQ(data)
+	case FormatMarkdown:
+		text, err := document.ExtractText(name, data)
+		if err != nil {
+			return nil, err
+		}
+		return splitMarkdown(text), nil
+	default:
+		text, err := document.ExtractText(name, data)
+		if err != nil {
+			return nil, err
+		}
+		return []section{{Text: text}}, nil
+	}
+}
+
+// splitMarkdown splits a Markdown document into sections at its headings
+func splitMarkdown(text string) []section {
+	var sections []section
+	current := section{}
+	var body strings.Builder
+
+	flush := func() {
+		if strings.TrimSpace(body.String()) != "" {
+			current.Text = body.String()
+			sections = append(sections, current)
+		}
+		body.Reset()
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			flush()
+			current = section{Title: strings.TrimSpace(strings.TrimLeft(trimmed, "#"))}
+			continue
+		}
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	flush()
+	return sections
+}
+
+// loadFAQ reads a CSV of questions and answers; each row becomes its own section.
+// Columns named "question" and "answer" are used when present, otherwise the first two columns.
+func loadFAQ(data []byte) ([]section, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FAQ CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("FAQ CSV is empty")
+	}
+
+	questionCol, answerCol := 0, 1
+	header := records[0]
+	hasHeader := false
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "question":
+			questionCol, hasHeader = i, true
+		case "answer":
+			answerCol, hasHeader = i, true
+		}
+	}
+	if hasHeader {
+		records = records[1:]
+	}
+
+	var sections []section
+	for _, record := range records {
+		if questionCol >= len(record) || answerCol >= len(record) {
+			continue
+		}
+		question := strings.TrimSpace(record[questionCol])
+		answer := strings.TrimSpace(record[answerCol])
+		if question == "" || answer == "" {
+			continue
+		}
+		sections = append(sections, section{
+			Title: question,
+			Text:  "Q: " + question + "\nA: " + answer,
+		})
+	}
+	return sections, nil
+}
@@ -4,72 +4,161 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openrouter"
+	"github.com/tmc/langchaingo/schema"
 
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/conversation"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/i18n"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/keylock"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
 )
 
-// Client handles communication with the OpenRouter LLM API
+// Client handles communication with the configured LLM provider
 type Client struct {
 	config    *config.Config
 	llmClient llms.Model
+	store     conversation.Store
+	tools     *ToolRegistry
+	retriever Retriever
+
+	// Model fallback chain
+	primary   modelRef
+	fallbacks []modelRef
+	mu        sync.Mutex
+	models    map[modelRef]llms.Model
+
+	// Per-user locks serializing conversation updates
+	convLocks *keylock.Locks
+
+	// Patterns of the models that accept images
+	vision []*regexp.Regexp
 }
 
-// NewClient creates a new LLM client using OpenRouter
+// NewClient creates a new LLM client using the configured provider
 func NewClient(cfg *config.Config) (*Client, error) {
-	if cfg.OpenRouterAPIKey == "" {
-		return nil, errors.New("OpenRouter API key is required")
+	// Initialize the provider client
+	primary := modelRef{Provider: cfg.LLMProvider, Name: defaultModelName(cfg, cfg.LLMProvider)}
+	llmClient, err := NewModel(cfg, primary.Provider, primary.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the fallback chain; fallback models are created on first use
+	var fallbacks []modelRef
+	for _, spec := range cfg.LLMFallbackModels {
+		ref := parseModelRef(cfg, spec)
+		if _, ok := providers[ref.Provider]; !ok {
+			return nil, fmt.Errorf("unknown LLM provider %q in fallback %q", ref.Provider, spec)
+		}
+		fallbacks = append(fallbacks, ref)
+	}
+
+	vision, err := compileVisionPatterns(cfg.LLMVisionModels)
+	if err != nil {
+		return nil, err
 	}
 
-	// Initialize OpenRouter client
-	llmClient, err := openrouter.New(
-		openrouter.WithAPIKey(cfg.OpenRouterAPIKey),
-		openrouter.WithModel(cfg.OpenRouterModelName),
-	)
+	// Initialize the conversation memory
+	store, err := conversation.NewStore(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize OpenRouter client: %w", err)
+		return nil, fmt.Errorf("failed to initialize conversation store: %w", err)
 	}
 
-	return &Client{
+	client := &Client{
 		config:    cfg,
 		llmClient: llmClient,
-	}, nil
+		store:     store,
+		tools:     NewToolRegistry(),
+		primary:   primary,
+		fallbacks: fallbacks,
+		models:    map[modelRef]llms.Model{primary: llmClient},
+		convLocks: keylock.New(),
+		vision:    vision,
+	}
+	if err := client.validateDefaults(); err != nil {
+		return nil, fmt.Errorf("invalid generation defaults: %w", err)
+	}
+	return client, nil
 }
 
+// StreamFunc receives chunks of a response as they are generated; returning an error stops the stream
+type StreamFunc func(ctx context.Context, chunk []byte) error
+
 // GenerateResponse generates a response using the configured LLM
 func (c *Client) GenerateResponse(ctx context.Context, request *models.LLMRequest) (*models.LLMResponse, error) {
-	// Construct the prompt with history if provided
-	prompt := request.MessageText
-	if len(request.History) > 0 {
-		// Simple way to include history, could be more sophisticated
-		historyText := "Chat history:\n"
-		for _, msg := range request.History {
-			historyText += msg + "\n"
+	return c.generate(ctx, request, nil)
+}
+
+// StreamResponse generates a response, passing each chunk to stream as it arrives.
+// The complete response is returned once generation finishes.
+func (c *Client) StreamResponse(ctx context.Context, request *models.LLMRequest, stream StreamFunc) (*models.LLMResponse, error) {
+	return c.generate(ctx, request, stream)
+}
+
+// generate produces a response, optionally streaming it, and remembers the exchange
+func (c *Client) generate(ctx context.Context, request *models.LLMRequest, stream StreamFunc) (*models.LLMResponse, error) {
+	// Use the remembered conversation unless the caller supplies its own history
+	var conv *models.Conversation
+	if len(request.History) == 0 && request.UserID != "" {
+		// Concurrent requests from the same user would otherwise overwrite each other's exchange
+		unlock := c.lockConversation(request.UserID)
+		defer unlock()
+
+		var err error
+		conv, err = c.loadConversation(ctx, request.UserID)
+		if err != nil {
+			log.Printf("Failed to load conversation for %s: %v", request.UserID, err)
 		}
-		prompt = historyText + "\nCurrent message: " + prompt
 	}
 
-	// Call the LLM to generate a response
-	completion, err := c.llmClient.Generate(ctx, []string{prompt}, llms.WithTemperature(0.7))
+	// Look up knowledge base passages relevant to the message; an image alone has no text to search for
+	var passages []models.RetrievedChunk
+	if c.retriever != nil && request.MessageText != "" {
+		var err error
+		passages, err = c.retriever.Retrieve(ctx, request.MessageText, c.config.KnowledgeTopK)
+		if err != nil {
+			log.Printf("Failed to retrieve knowledge for %s: %v", request.UserID, err)
+		}
+	}
+
+	// Call the LLM, running any tools it requests, to generate a response
+	messages := c.buildMessages(request, conv, passages)
+	choice, used, toolCalls, err := c.generateWithTools(ctx, request, messages, stream)
 	if err != nil {
 		return &models.LLMResponse{
-			Error: fmt.Sprintf("failed to generate response: %v", err),
+			Error:     fmt.Sprintf("failed to generate response: %v", err),
+			ToolCalls: toolCalls,
 		}, nil
 	}
 
 	// Extract the response text
-	if len(completion.Generations) > 0 && len(completion.Generations[0]) > 0 {
-		return &models.LLMResponse{
-			ResponseText: completion.Generations[0][0].Text,
-		}, nil
+	responseText := choice.Content
+
+	// Remember the exchange for future prompts
+	if conv != nil {
+		now := time.Now()
+		conv.Turns = append(conv.Turns,
+			models.ConversationTurn{Role: models.RoleUser, Text: userTurnText(request), Timestamp: now},
+			models.ConversationTurn{Role: models.RoleAssistant, Text: responseText, Timestamp: now},
+		)
+		conv.UpdatedAt = now
+		if err := c.store.Save(ctx, conv); err != nil {
+			log.Printf("Failed to save conversation for %s: %v", request.UserID, err)
+		}
 	}
 
-	// Return an error if no response
 	return &models.LLMResponse{
-		Error: "no response generated from the LLM",
+		ResponseText: responseText,
+		Provider:     used.Provider,
+		Model:        used.Name,
+		ToolCalls:    toolCalls,
 	}, nil
 }
 
@@ -93,4 +182,141 @@ func (c *Client) ProcessMessage(ctx context.Context, userID, messageText string)
 	}
 
 	return response.ResponseText, nil
-} 
\ No newline at end of file
+}
+
+// ResetConversation forgets everything remembered about a user's conversation
+func (c *Client) ResetConversation(ctx context.Context, userID string) error {
+	unlock := c.lockConversation(userID)
+	defer unlock()
+
+	return c.store.Delete(ctx, userID)
+}
+
+// languagePrompt tells the model which language to reply in
+func languagePrompt(lang string) string {
+	prompt := fmt.Sprintf("Always reply in %s, whatever language the instructions, knowledge base passages, documents or earlier messages are written in.", i18n.Name(lang))
+	if lang == i18n.Javanese {
+		// Models drift into Indonesian unless the register is spelled out
+		prompt += " Use polite Javanese (krama), not Indonesian, unless the user writes in ngoko."
+	}
+	return prompt
+}
+
+// businessHoursPrompt describes the team's availability to the model
+func businessHoursPrompt(status *models.BusinessHours) string {
+	const layout = "Monday, 2 January 2006 15:04 MST"
+	hours := fmt.Sprintf("Our team's business hours are %s (%s).", status.Schedule, status.Timezone)
+	if status.Open {
+		if status.ClosesAt != nil {
+			return fmt.Sprintf("%s The team is available now, until %s.", hours, status.ClosesAt.Format(layout))
+		}
+		return hours + " The team is available now."
+	}
+	if status.OpensAt != nil {
+		return fmt.Sprintf("%s The team is closed now and will be back %s; don't promise a reply from a person before then.", hours, status.OpensAt.Format(layout))
+	}
+	return hours + " The team is closed now; don't promise a reply from a person."
+}
+
+// flowsPrompt lists the forms the model may start with the start_flow tool
+func flowsPrompt(flows []models.FlowSummary) string {
+	var b strings.Builder
+	b.WriteString("You can start these forms with the start_flow tool when the user wants what they are for. The form asks its own questions after your reply, so don't ask them yourself:")
+	for _, flow := range flows {
+		fmt.Fprintf(&b, "\n- %s", flow.Name)
+		if flow.Description != "" {
+			fmt.Fprintf(&b, ": %s", flow.Description)
+		}
+	}
+	return b.String()
+}
+
+// buildMessages constructs the chat messages sent to the LLM
+func (c *Client) buildMessages(request *models.LLMRequest, conv *models.Conversation, passages []models.RetrievedChunk) []llms.MessageContent {
+	var messages []llms.MessageContent
+
+	// Reply in the user's language, even when the knowledge, documents or history are in another
+	if request.Language != "" {
+		messages = append(messages, llms.TextParts(schema.ChatMessageTypeSystem, languagePrompt(request.Language)))
+	}
+
+	// Tell the model the user's local time so it can resolve dates such as "tomorrow at 9"
+	if request.Timezone != "" {
+		if loc, err := time.LoadLocation(request.Timezone); err == nil {
+			messages = append(messages, llms.TextParts(schema.ChatMessageTypeSystem,
+				fmt.Sprintf("The user's timezone is %s, where it is now %s.", loc, time.Now().In(loc).Format("Monday, 2 January 2006 15:04"))))
+		}
+	}
+
+	// Tell the model whether our team is available, so it doesn't promise a reply from a person
+	if request.BusinessHours != nil {
+		messages = append(messages, llms.TextParts(schema.ChatMessageTypeSystem, businessHoursPrompt(request.BusinessHours)))
+	}
+
+	// Offer the forms the WhatsApp service can run
+	if len(request.Flows) > 0 {
+		messages = append(messages, llms.TextParts(schema.ChatMessageTypeSystem, flowsPrompt(request.Flows)))
+	}
+
+	// Include the retrieved knowledge
+	if len(passages) > 0 {
+		messages = append(messages, knowledgeMessage(passages))
+	}
+
+	// Include the documents the user shared in the chat
+	if message, ok := documentsMessage(request, c.config.DocumentTokenBudget); ok {
+		messages = append(messages, message)
+	}
+
+	// Include the remembered conversation
+	if conv != nil {
+		if conv.Summary != "" {
+			messages = append(messages, llms.TextParts(schema.ChatMessageTypeSystem,
+				"Summary of the earlier conversation:\n"+conv.Summary))
+		}
+		for _, turn := range conv.Turns {
+			role := schema.ChatMessageTypeHuman
+			if turn.Role == models.RoleAssistant {
+				role = schema.ChatMessageTypeAI
+			}
+			messages = append(messages, llms.TextParts(role, turn.Text))
+		}
+	}
+
+	// Construct the prompt with history if provided
+	prompt := request.MessageText
+	if len(request.History) > 0 {
+		// Simple way to include history, could be more sophisticated
+		historyText := "Chat history:\n"
+		for _, msg := range request.History {
+			historyText += msg + "\n"
+		}
+		prompt = historyText + "\nCurrent message: " + prompt
+	}
+
+	// Attach the images the user sent, with their caption as the text
+	if len(request.Attachments) == 0 {
+		return append(messages, llms.TextParts(schema.ChatMessageTypeHuman, prompt))
+	}
+	if prompt == "" {
+		prompt = imageOnlyPrompt
+	}
+	message := llms.TextParts(schema.ChatMessageTypeHuman, prompt)
+	for _, attachment := range request.Attachments {
+		if attachment.Type == models.MediaImage {
+			message.Parts = append(message.Parts, llms.BinaryPart(attachment.MIMEType, attachment.Data))
+		}
+	}
+	return append(messages, message)
+}
+
+// userTurnText returns how the user's message is remembered; images aren't stored, only noted
+func userTurnText(request *models.LLMRequest) string {
+	text := request.MessageText
+	for _, attachment := range request.Attachments {
+		if attachment.Type == models.MediaImage {
+			text = strings.TrimSpace(text + " [image]")
+		}
+	}
+	return text
+}
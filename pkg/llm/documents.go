@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// documentExcerpt is a chunk of a shared document considered for the prompt
+type documentExcerpt struct {
+	doc    int
+	chunk  int
+	text   string
+	tokens int
+	score  int
+}
+
+// documentsMessage presents the documents the user shared, within the document token budget.
+// When they don't fit, the chunks sharing the most words with the message are chosen.
+func documentsMessage(request *models.LLMRequest, budget int) (llms.MessageContent, bool) {
+	var excerpts []documentExcerpt
+	total := 0
+	for i, doc := range request.Documents {
+		for j, chunk := range doc.Chunks {
+			tokens := estimateTokens(chunk)
+			excerpts = append(excerpts, documentExcerpt{doc: i, chunk: j, text: chunk, tokens: tokens})
+			total += tokens
+		}
+	}
+	if len(excerpts) == 0 {
+		return llms.MessageContent{}, false
+	}
+
+	// Pick the most relevant chunks that fit, then restore the document order
+	if budget > 0 && total > budget {
+		terms := searchTerms(request.MessageText)
+		for i := range excerpts {
+			excerpts[i].score = termMatches(excerpts[i].text, terms)
+		}
+		sort.SliceStable(excerpts, func(i, j int) bool {
+			return excerpts[i].score > excerpts[j].score
+		})
+
+		var selected []documentExcerpt
+		used := 0
+		for _, excerpt := range excerpts {
+			if used+excerpt.tokens <= budget {
+				selected = append(selected, excerpt)
+				used += excerpt.tokens
+			}
+		}
+		sort.Slice(selected, func(i, j int) bool {
+			if selected[i].doc != selected[j].doc {
+				return selected[i].doc < selected[j].doc
+			}
+			return selected[i].chunk < selected[j].chunk
+		})
+		excerpts = selected
+	}
+
+	var text strings.Builder
+	text.WriteString("The user shared the documents below in this chat. Answer their questions about them from these excerpts, ")
+	text.WriteString("and say so when the excerpts don't contain the answer.\n")
+	for _, excerpt := range excerpts {
+		doc := request.Documents[excerpt.doc]
+		fmt.Fprintf(&text, "\n[%s, part %d of %d]\n%s\n", doc.Name, excerpt.chunk+1, len(doc.Chunks), excerpt.text)
+	}
+	return llms.TextParts(schema.ChatMessageTypeSystem, text.String()), true
+}
+
+// searchTerms returns the distinct lowercase words of a message, skipping short ones
+func searchTerms(message string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= 3 && !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// termMatches counts the terms that appear in a text
+func termMatches(text string, terms []string) int {
+	text = strings.ToLower(text)
+	matches := 0
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			matches++
+		}
+	}
+	return matches
+}
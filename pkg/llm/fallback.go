@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+)
+
+// modelRef identifies a model served by a provider
+type modelRef struct {
+	Provider string
+	Name     string
+}
+
+// String formats the reference as "provider:model"
+func (r modelRef) String() string {
+	return r.Provider + ":" + r.Name
+}
+
+// parseModelRef parses "provider:model" or a bare model name served by the default provider.
+// Model names may contain colons themselves (e.g. "meta-llama/llama-3-8b-instruct:free"),
+// so the prefix is only treated as a provider when it names a known one.
+func parseModelRef(cfg *config.Config, spec string) modelRef {
+	spec = strings.TrimSpace(spec)
+	ref := modelRef{Provider: cfg.LLMProvider, Name: spec}
+	if provider, name, ok := strings.Cut(spec, ":"); ok {
+		if _, known := providers[provider]; known {
+			ref = modelRef{Provider: provider, Name: name}
+		}
+	}
+	if ref.Name == "" {
+		ref.Name = defaultModelName(cfg, ref.Provider)
+	}
+	return ref
+}
+
+// defaultModelName returns the configured model name for a provider
+func defaultModelName(cfg *config.Config, provider string) string {
+	switch provider {
+	case config.ProviderOpenAI:
+		return cfg.OpenAIModelName
+	case config.ProviderOllama:
+		return cfg.OllamaModelName
+	default:
+		return cfg.OpenRouterModelName
+	}
+}
+
+// model returns a cached model for the reference, creating it on first use
+func (c *Client) model(ref modelRef) (llms.Model, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if m, ok := c.models[ref]; ok {
+		return m, nil
+	}
+	m, err := NewModel(c.config, ref.Provider, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.models[ref] = m
+	return m, nil
+}
+
+// allowsModel reports whether a request may select the model: only the primary model and the
+// configured fallbacks are served, so callers can't reach unconfigured models or grow the cache
+func (c *Client) allowsModel(ref modelRef) bool {
+	if ref == c.primary {
+		return true
+	}
+	for _, fallback := range c.fallbacks {
+		if ref == fallback {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates returns the models to try in order: the override, the primary model and the fallbacks.
+// Overrides outside the configured chain are ignored.
+func (c *Client) candidates(override string) []modelRef {
+	var refs []modelRef
+	if override != "" {
+		if ref := parseModelRef(c.config, override); c.allowsModel(ref) {
+			refs = append(refs, ref)
+		}
+	}
+	refs = append(refs, c.primary)
+	refs = append(refs, c.fallbacks...)
+
+	// Drop duplicates while keeping the order
+	seen := make(map[modelRef]bool, len(refs))
+	unique := refs[:0]
+	for _, ref := range refs {
+		if !seen[ref] {
+			seen[ref] = true
+			unique = append(unique, ref)
+		}
+	}
+	return unique
+}
+
+// generateWithFallback tries each candidate model until one succeeds or a non-retryable error occurs.
+// Once a streamed response has started, its model is never swapped out.
+func (c *Client) generateWithFallback(ctx context.Context, override string, messages []llms.MessageContent, stream StreamFunc, options ...llms.CallOption) (*llms.ContentResponse, modelRef, error) {
+	streamed := false
+	if stream != nil {
+		options = append(options, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			streamed = true
+			return stream(ctx, chunk)
+		}))
+	}
+
+	var lastErr error
+	for _, ref := range c.candidates(override) {
+		m, err := c.model(ref)
+		if err != nil {
+			log.Printf("Skipping model %s: %v", ref, err)
+			lastErr = err
+			continue
+		}
+
+		completion, err := m.GenerateContent(ctx, c.messagesFor(ref, messages), options...)
+		if err == nil {
+			return completion, ref, nil
+		}
+		lastErr = fmt.Errorf("%s: %w", ref, err)
+
+		// Only move on to the next model when another attempt could succeed
+		if ctx.Err() != nil || streamed || !isRetryable(err) {
+			return nil, ref, lastErr
+		}
+		log.Printf("Model %s failed, trying next fallback: %v", ref, err)
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no LLM models configured")
+	}
+	return nil, modelRef{}, lastErr
+}
+
+// isRetryable reports whether an error is likely to be resolved by trying another model
+func isRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// Provider clients only surface the HTTP status in the error message
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"429", "rate limit", "too many requests", "quota",
+		"500", "502", "503", "504", "overloaded", "unavailable",
+		"timeout", "connection refused", "connection reset", "no such host",
+		"empty response", "no response",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
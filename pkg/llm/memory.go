@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// lockConversation locks a user's conversation until the returned function is called
+func (c *Client) lockConversation(userID string) func() {
+	return c.convLocks.Lock(userID)
+}
+
+// loadConversation loads a user's conversation and fits it into the token budget
+func (c *Client) loadConversation(ctx context.Context, userID string) (*models.Conversation, error) {
+	conv, err := c.store.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversationTokens(conv) <= c.config.MemoryTokenBudget {
+		return conv, nil
+	}
+
+	// Fold older turns into the summary, falling back to truncation on failure
+	if c.config.MemoryMode == config.MemoryModeSummary {
+		if err := c.summarizeConversation(ctx, conv); err != nil {
+			log.Printf("Failed to summarize conversation for %s: %v", userID, err)
+		}
+	}
+	truncateConversation(conv, c.config.MemoryTokenBudget)
+
+	return conv, nil
+}
+
+// summarizeConversation replaces all but the most recent turns with a running summary
+func (c *Client) summarizeConversation(ctx context.Context, conv *models.Conversation) error {
+	keep := c.config.MemoryRecentTurns
+	if keep < 0 {
+		keep = 0
+	}
+	if len(conv.Turns) <= keep {
+		return nil
+	}
+	older := conv.Turns[:len(conv.Turns)-keep]
+
+	// Ask the LLM to merge the older turns into the existing summary
+	var prompt strings.Builder
+	prompt.WriteString(c.config.SummarizerPrompt)
+	prompt.WriteString("\n\nExisting summary:\n")
+	if conv.Summary == "" {
+		prompt.WriteString("(none)")
+	} else {
+		prompt.WriteString(conv.Summary)
+	}
+	prompt.WriteString("\n\nNew messages:\n")
+	for _, turn := range older {
+		prompt.WriteString(turn.Role + ": " + turn.Text + "\n")
+	}
+
+	messages := []llms.MessageContent{llms.TextParts(schema.ChatMessageTypeHuman, prompt.String())}
+	completion, _, err := c.generateWithFallback(ctx, "", messages, nil, llms.WithTemperature(0))
+	if err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return errors.New("failed to generate summary: no response generated from the LLM")
+	}
+
+	conv.Summary = strings.TrimSpace(completion.Choices[0].Content)
+	conv.Turns = append([]models.ConversationTurn(nil), conv.Turns[len(older):]...)
+	return nil
+}
+
+// truncateConversation drops the oldest turns until the conversation fits the budget
+func truncateConversation(conv *models.Conversation, budget int) {
+	for len(conv.Turns) > 1 && conversationTokens(conv) > budget {
+		conv.Turns = conv.Turns[1:]
+	}
+}
+
+// conversationTokens estimates the prompt size of a conversation
+func conversationTokens(conv *models.Conversation) int {
+	tokens := estimateTokens(conv.Summary)
+	for _, turn := range conv.Turns {
+		tokens += estimateTokens(turn.Text)
+	}
+	return tokens
+}
+
+// estimateTokens approximates the number of tokens in a text, at about four characters per token
+func estimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
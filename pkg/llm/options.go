@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// ValidateRequest checks the model and the optional generation parameters of an LLM request
+func (c *Client) ValidateRequest(request *models.LLMRequest) error {
+	if request.Model != "" && !c.allowsModel(parseModelRef(c.config, request.Model)) {
+		return fmt.Errorf("model %q is not configured", request.Model)
+	}
+	if request.Temperature != nil && (*request.Temperature < 0 || *request.Temperature > 2) {
+		return errors.New("temperature must be between 0 and 2")
+	}
+	if request.MaxTokens != nil && *request.MaxTokens <= 0 {
+		return errors.New("max_tokens must be positive")
+	}
+	if request.TopP != nil && (*request.TopP <= 0 || *request.TopP > 1) {
+		return errors.New("top_p must be greater than 0 and at most 1")
+	}
+	if len(request.Stop) > 4 {
+		return errors.New("at most 4 stop sequences are allowed")
+	}
+	for _, stop := range request.Stop {
+		if stop == "" {
+			return errors.New("stop sequences must not be empty")
+		}
+	}
+	if request.Seed != nil && *request.Seed < 0 {
+		return errors.New("seed must not be negative")
+	}
+
+	// Refuse parameters a model in the chain would ignore rather than answer without them
+	if request.TopP != nil || request.Seed != nil {
+		for _, ref := range c.candidates(request.Model) {
+			if !samplingProviders[ref.Provider] {
+				return fmt.Errorf("top_p and seed are not supported by model %s", ref)
+			}
+		}
+	}
+	return nil
+}
+
+// validateDefaults checks the configured generation defaults the same way as request values
+func (c *Client) validateDefaults() error {
+	defaults := &models.LLMRequest{Temperature: &c.config.Temperature}
+	if c.config.MaxTokens != 0 {
+		defaults.MaxTokens = &c.config.MaxTokens
+	}
+	if c.config.TopP != 0 {
+		defaults.TopP = &c.config.TopP
+	}
+	if len(c.config.StopSequences) > 0 {
+		defaults.Stop = c.config.StopSequences
+	}
+	if c.config.Seed != 0 {
+		defaults.Seed = &c.config.Seed
+	}
+	return c.ValidateRequest(defaults)
+}
+
+// callOptions merges the deployment defaults with the request's generation parameters
+func (c *Client) callOptions(request *models.LLMRequest) []llms.CallOption {
+	temperature := c.config.Temperature
+	if request.Temperature != nil {
+		temperature = *request.Temperature
+	}
+	options := []llms.CallOption{llms.WithTemperature(temperature)}
+
+	maxTokens := c.config.MaxTokens
+	if request.MaxTokens != nil {
+		maxTokens = *request.MaxTokens
+	}
+	if maxTokens > 0 {
+		options = append(options, llms.WithMaxTokens(maxTokens))
+	}
+
+	topP := c.config.TopP
+	if request.TopP != nil {
+		topP = *request.TopP
+	}
+	if topP > 0 {
+		options = append(options, llms.WithTopP(topP))
+	}
+
+	stop := c.config.StopSequences
+	if request.Stop != nil {
+		stop = request.Stop
+	}
+	if len(stop) > 0 {
+		options = append(options, llms.WithStopWords(stop))
+	}
+
+	if request.Seed != nil {
+		options = append(options, llms.WithSeed(*request.Seed))
+	} else if c.config.Seed > 0 {
+		options = append(options, llms.WithSeed(c.config.Seed))
+	}
+
+	return options
+}
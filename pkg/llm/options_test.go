@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/keylock"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// recordingModel answers every call and records the options it was given
+type recordingModel struct {
+	options llms.CallOptions
+}
+
+func (m *recordingModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.options = llms.CallOptions{}
+	for _, option := range options {
+		option(&m.options)
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "ok"}}}, nil
+}
+
+func (m *recordingModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// newTestClient creates a client whose primary model is served by the given provider
+func newTestClient(cfg *config.Config, provider string, fallbacks ...string) (*Client, *recordingModel) {
+	cfg.LLMProvider = provider
+	model := &recordingModel{}
+	primary := modelRef{Provider: provider, Name: defaultModelName(cfg, provider)}
+
+	c := &Client{
+		config:    cfg,
+		llmClient: model,
+		tools:     NewToolRegistry(),
+		primary:   primary,
+		models:    map[modelRef]llms.Model{primary: model},
+		convLocks: keylock.New(),
+	}
+	for _, spec := range fallbacks {
+		c.fallbacks = append(c.fallbacks, parseModelRef(cfg, spec))
+	}
+	return c, model
+}
+
+func TestCallOptionsSentToProvider(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+	integer := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		request models.LLMRequest
+		want    llms.CallOptions
+	}{
+		{
+			name: "defaults",
+			cfg:  config.Config{Temperature: 0.7, MaxTokens: 256, TopP: 0.9, Seed: 42, StopSequences: []string{"END"}},
+			want: llms.CallOptions{Temperature: 0.7, MaxTokens: 256, TopP: 0.9, Seed: 42, StopWords: []string{"END"}},
+		},
+		{
+			name: "request overrides",
+			cfg:  config.Config{Temperature: 0.7, MaxTokens: 256, TopP: 0.9, Seed: 42},
+			request: models.LLMRequest{
+				Temperature: float(0),
+				MaxTokens:   integer(64),
+				TopP:        float(0.5),
+				Seed:        integer(7),
+				Stop:        []string{"\n\n"},
+			},
+			want: llms.CallOptions{Temperature: 0, MaxTokens: 64, TopP: 0.5, Seed: 7, StopWords: []string{"\n\n"}},
+		},
+		{
+			name: "unset parameters are left to the provider",
+			cfg:  config.Config{Temperature: 0.2},
+			want: llms.CallOptions{Temperature: 0.2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, model := newTestClient(&tt.cfg, config.ProviderOllama)
+			request := tt.request
+			request.MessageText = "hello"
+
+			if err := c.ValidateRequest(&request); err != nil {
+				t.Fatalf("ValidateRequest() error = %v", err)
+			}
+			response, err := c.GenerateResponse(context.Background(), &request)
+			if err != nil || response.Error != "" {
+				t.Fatalf("GenerateResponse() error = %v, %q", err, response.Error)
+			}
+
+			got := model.options
+			got.StreamingFunc = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("options sent = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRequestSamplingSupport(t *testing.T) {
+	topP := 0.5
+	seed := 7
+
+	tests := []struct {
+		name      string
+		provider  string
+		fallbacks []string
+		request   models.LLMRequest
+		wantErr   bool
+	}{
+		{name: "ollama honors top_p", provider: config.ProviderOllama, request: models.LLMRequest{TopP: &topP}},
+		{name: "ollama honors seed", provider: config.ProviderOllama, request: models.LLMRequest{Seed: &seed}},
+		{name: "openai drops top_p", provider: config.ProviderOpenAI, request: models.LLMRequest{TopP: &topP}, wantErr: true},
+		{name: "openrouter drops seed", provider: config.ProviderOpenRouter, request: models.LLMRequest{Seed: &seed}, wantErr: true},
+		{name: "fallback drops seed", provider: config.ProviderOllama, fallbacks: []string{"openai:gpt-4o-mini"}, request: models.LLMRequest{Seed: &seed}, wantErr: true},
+		{name: "openai without sampling parameters", provider: config.ProviderOpenAI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(&config.Config{Temperature: 0.7}, tt.provider, tt.fallbacks...)
+			err := c.ValidateRequest(&tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		cfg      config.Config
+		wantErr  bool
+	}{
+		{name: "valid", provider: config.ProviderOllama, cfg: config.Config{Temperature: 0.7, TopP: 0.9, Seed: 1}},
+		{name: "top_p out of range", provider: config.ProviderOllama, cfg: config.Config{Temperature: 0.7, TopP: 1.5}, wantErr: true},
+		{name: "negative seed", provider: config.ProviderOllama, cfg: config.Config{Temperature: 0.7, Seed: -1}, wantErr: true},
+		{name: "temperature out of range", provider: config.ProviderOllama, cfg: config.Config{Temperature: 3}, wantErr: true},
+		{name: "top_p with openai", provider: config.ProviderOpenAI, cfg: config.Config{Temperature: 0.7, TopP: 0.9}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(&tt.cfg, tt.provider)
+			err := c.validateDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
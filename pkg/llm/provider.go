@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/tmc/langchaingo/llms/openrouter"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+)
+
+// providerFactory creates a model for a provider; an empty model name selects the provider's configured default
+type providerFactory func(cfg *config.Config, model string) (llms.Model, error)
+
+// providers maps provider names to their factories
+var providers = map[string]providerFactory{
+	config.ProviderOpenRouter: newOpenRouterModel,
+	config.ProviderOpenAI:     newOpenAIModel,
+	config.ProviderOllama:     newOllamaModel,
+}
+
+// samplingProviders are the providers whose langchaingo client forwards top_p and seed.
+// The OpenAI client (also used for OpenRouter) silently drops both.
+var samplingProviders = map[string]bool{
+	config.ProviderOllama: true,
+}
+
+// NewModel creates a langchaingo model for the named provider
+func NewModel(cfg *config.Config, provider, model string) (llms.Model, error) {
+	factory, ok := providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q", provider)
+	}
+	return factory(cfg, model)
+}
+
+// newOpenRouterModel creates a model served by OpenRouter
+func newOpenRouterModel(cfg *config.Config, model string) (llms.Model, error) {
+	if cfg.OpenRouterAPIKey == "" {
+		return nil, errors.New("OpenRouter API key is required")
+	}
+	if model == "" {
+		model = cfg.OpenRouterModelName
+	}
+
+	llmClient, err := openrouter.New(
+		openrouter.WithAPIKey(cfg.OpenRouterAPIKey),
+		openrouter.WithModel(model),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize OpenRouter client: %w", err)
+	}
+	return llmClient, nil
+}
+
+// newOpenAIModel creates a model served by OpenAI or any OpenAI-compatible endpoint
+func newOpenAIModel(cfg *config.Config, model string) (llms.Model, error) {
+	if model == "" {
+		model = cfg.OpenAIModelName
+	}
+
+	// Self-hosted compatible servers usually don't check the key, but the client requires one
+	token := cfg.OpenAIAPIKey
+	if token == "" {
+		if cfg.OpenAIBaseURL == "" {
+			return nil, errors.New("OpenAI API key is required")
+		}
+		token = "unused"
+	}
+
+	opts := []openai.Option{
+		openai.WithToken(token),
+		openai.WithModel(model),
+	}
+	if cfg.OpenAIBaseURL != "" {
+		opts = append(opts, openai.WithBaseURL(cfg.OpenAIBaseURL))
+	}
+
+	llmClient, err := openai.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize OpenAI client: %w", err)
+	}
+	return llmClient, nil
+}
+
+// newOllamaModel creates a model served by a local Ollama instance
+func newOllamaModel(cfg *config.Config, model string) (llms.Model, error) {
+	if model == "" {
+		model = cfg.OllamaModelName
+	}
+
+	llmClient, err := ollama.New(
+		ollama.WithServerURL(cfg.OllamaServerURL),
+		ollama.WithModel(model),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Ollama client: %w", err)
+	}
+	return llmClient, nil
+}
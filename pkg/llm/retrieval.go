@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/knowledge"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Retriever finds knowledge passages relevant to a question
+type Retriever interface {
+	Retrieve(ctx context.Context, query string, k int) ([]models.RetrievedChunk, error)
+}
+
+// NewEmbedder creates an embedder using the configured embedding provider and model
+func NewEmbedder(cfg *config.Config) (knowledge.Embedder, error) {
+	if cfg.EmbeddingProvider == config.ProviderOpenRouter {
+		return nil, errors.New("OpenRouter does not provide embeddings; set EMBEDDING_PROVIDER to openai or ollama")
+	}
+
+	m, err := NewModel(cfg, cfg.EmbeddingProvider, cfg.EmbeddingModel)
+	if err != nil {
+		return nil, err
+	}
+	embedder, ok := m.(knowledge.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("provider %q does not support embeddings", cfg.EmbeddingProvider)
+	}
+	return embedder, nil
+}
+
+// SetRetriever enables retrieval-augmented answers from a knowledge base
+func (c *Client) SetRetriever(retriever Retriever) {
+	c.retriever = retriever
+}
+
+// knowledgeMessage presents retrieved passages to the model with instructions to cite them
+func knowledgeMessage(passages []models.RetrievedChunk) llms.MessageContent {
+	var text strings.Builder
+	text.WriteString("Answer using the knowledge base passages below when they are relevant. ")
+	text.WriteString("Cite the passages you use by their number, e.g. [1]. ")
+	text.WriteString("Never invent prices, policies or other facts; if the passages don't cover the question, say you don't know.\n")
+	for i, passage := range passages {
+		fmt.Fprintf(&text, "\n[%d] (source: %s)\n%s\n", i+1, passage.Source, passage.Text)
+	}
+	return llms.TextParts(schema.ChatMessageTypeSystem, text.String())
+}
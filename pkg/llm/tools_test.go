@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// scriptedModel streams and returns one scripted choice per call
+type scriptedModel struct {
+	choices []*llms.ContentChoice
+	calls   int
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var opts llms.CallOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	choice := m.choices[m.calls]
+	m.calls++
+	if opts.StreamingFunc != nil && choice.Content != "" {
+		if err := opts.StreamingFunc(ctx, []byte(choice.Content)); err != nil {
+			return nil, err
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+func (m *scriptedModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestStreamSkipsTextSentWithToolCalls(t *testing.T) {
+	toolCall := &schema.FunctionCall{Name: "lookup", Arguments: `{}`}
+
+	tests := []struct {
+		name          string
+		maxIterations int
+		choices       []*llms.ContentChoice
+		want          string
+	}{
+		{
+			name:          "answer after a tool call",
+			maxIterations: 5,
+			choices: []*llms.ContentChoice{
+				{Content: "Let me check that for you. ", FuncCall: toolCall},
+				{Content: "Your order has shipped."},
+			},
+			want: "Your order has shipped.",
+		},
+		{
+			name:          "text of a tool call at the limit is the answer",
+			maxIterations: 1,
+			choices: []*llms.ContentChoice{
+				{Content: "Checking. ", FuncCall: toolCall},
+				{Content: "It is on its way.", FuncCall: toolCall},
+			},
+			want: "It is on its way.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(&config.Config{Temperature: 0.7, ToolMaxIterations: tt.maxIterations}, config.ProviderOllama)
+			model := &scriptedModel{choices: tt.choices}
+			c.llmClient = model
+			c.models[c.primary] = model
+			if err := c.RegisterTool(Tool{
+				Name:    "lookup",
+				Handler: func(ctx context.Context, arguments string) (string, error) { return "shipped", nil },
+			}); err != nil {
+				t.Fatalf("RegisterTool() error = %v", err)
+			}
+
+			var streamed strings.Builder
+			response, err := c.StreamResponse(context.Background(), &models.LLMRequest{MessageText: "where is my order?"}, func(ctx context.Context, chunk []byte) error {
+				streamed.Write(chunk)
+				return nil
+			})
+			if err != nil || response.Error != "" {
+				t.Fatalf("StreamResponse() error = %v, %q", err, response.Error)
+			}
+
+			if streamed.String() != tt.want {
+				t.Errorf("streamed %q, want %q", streamed.String(), tt.want)
+			}
+			if response.ResponseText != streamed.String() {
+				t.Errorf("ResponseText = %q, want the streamed text %q", response.ResponseText, streamed.String())
+			}
+		})
+	}
+}
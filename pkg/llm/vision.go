@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+)
+
+// imageOnlyPrompt stands in for the caption of an image sent without one
+const imageOnlyPrompt = "(The user sent this image without a caption.)"
+
+// imagesUnsupportedNote replaces images for models that can't see them
+const imagesUnsupportedNote = "[The user sent an image, but you can't see images. Tell them so and ask them to describe what it shows in words.]"
+
+// compileVisionPatterns compiles model name patterns, where * matches any text, into
+// case-insensitive regular expressions. Model names contain slashes, so path.Match won't do.
+func compileVisionPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		re, err := regexp.Compile("(?i)^" + strings.Join(parts, ".*") + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid vision model pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// supportsImages reports whether a model accepts images, matching either its name or
+// "provider:model" against the vision model patterns
+func (c *Client) supportsImages(ref modelRef) bool {
+	for _, re := range c.vision {
+		if re.MatchString(ref.Name) || re.MatchString(ref.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// messagesFor adapts images in the messages to what the model accepts: OpenAI-compatible
+// APIs take them as data URLs, Ollama as raw bytes, and text-only models get a note instead
+func (c *Client) messagesFor(ref modelRef, messages []llms.MessageContent) []llms.MessageContent {
+	if !hasImages(messages) {
+		return messages
+	}
+	vision := c.supportsImages(ref)
+
+	adapted := make([]llms.MessageContent, len(messages))
+	for i, msg := range messages {
+		var parts []llms.ContentPart
+		unseen := false
+		for _, part := range msg.Parts {
+			image, ok := part.(llms.BinaryContent)
+			switch {
+			case !ok:
+				parts = append(parts, part)
+			case !vision:
+				unseen = true
+			case ref.Provider == config.ProviderOllama:
+				parts = append(parts, image)
+			default:
+				parts = append(parts, llms.ImageURLPart(dataURL(image)))
+			}
+		}
+		if unseen {
+			parts = appendText(parts, imagesUnsupportedNote)
+		}
+		adapted[i] = llms.MessageContent{Role: msg.Role, Parts: parts}
+	}
+	return adapted
+}
+
+// hasImages reports whether any of the messages carries an image
+func hasImages(messages []llms.MessageContent) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if _, ok := part.(llms.BinaryContent); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendText adds text to the message's text part. Ollama accepts only one text part per
+// message, so it is merged rather than added as another part.
+func appendText(parts []llms.ContentPart, text string) []llms.ContentPart {
+	for i, part := range parts {
+		if t, ok := part.(llms.TextContent); ok {
+			parts[i] = llms.TextContent{Text: strings.TrimSpace(t.Text + "\n\n" + text)}
+			return parts
+		}
+	}
+	return append(parts, llms.TextContent{Text: text})
+}
+
+// dataURL encodes an image as a data URL
+func dataURL(image llms.BinaryContent) string {
+	return "data:" + image.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(image.Data)
+}
@@ -0,0 +1,298 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxWebhookResponseSize limits how much of a webhook response is read
+const maxWebhookResponseSize = 1 << 20
+
+// maxWebhookResultLength limits how much of a webhook result is passed back to the model
+const maxWebhookResultLength = 4000
+
+// placeholderPattern matches {param} placeholders filled from the call arguments and
+// reserved {{name}} placeholders filled from the request
+var placeholderPattern = regexp.MustCompile(`\{\{\w+\}\}|\{\w+\}`)
+
+// WebhookToolDefinition declares an HTTP endpoint exposed to the model as a tool
+type WebhookToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	// URL may contain {param} placeholders that are replaced by the call arguments, and
+	// {{user_id}}, which is replaced by the request's user_id and can't be set by the model
+	URL    string `json:"url"`
+	Method string `json:"method"`
+	// Body is the JSON body template; its string values may contain the same placeholders
+	Body map[string]interface{} `json:"body,omitempty"`
+	// Headers are sent with every call; values may reference environment variables as ${VAR}
+	Headers map[string]string `json:"headers,omitempty"`
+	// ResponseFields are dotted paths (e.g. "data.status" or "items.0.name") extracted from a JSON response.
+	// The whole response body is returned when empty.
+	ResponseFields []string `json:"response_fields,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+}
+
+// webhookToolsFile is the layout of the webhook tools configuration file
+type webhookToolsFile struct {
+	Tools []WebhookToolDefinition `json:"tools"`
+}
+
+// LoadWebhookTools reads webhook tool definitions from a JSON file
+func LoadWebhookTools(path string, defaultTimeout time.Duration) ([]Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook tools file: %w", err)
+	}
+
+	var file webhookToolsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook tools file: %w", err)
+	}
+
+	tools := make([]Tool, 0, len(file.Tools))
+	for _, def := range file.Tools {
+		tool, err := NewWebhookTool(def, defaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// NewWebhookTool creates a tool that calls an HTTP endpoint
+func NewWebhookTool(def WebhookToolDefinition, defaultTimeout time.Duration) (Tool, error) {
+	if def.Name == "" {
+		return Tool{}, errors.New("webhook tool name is required")
+	}
+	if def.URL == "" {
+		return Tool{}, fmt.Errorf("webhook tool %q has no URL", def.Name)
+	}
+	if def.Method == "" {
+		def.Method = http.MethodGet
+	}
+	def.Method = strings.ToUpper(def.Method)
+
+	timeout := defaultTimeout
+	if def.TimeoutSeconds > 0 {
+		timeout = time.Duration(def.TimeoutSeconds) * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	return Tool{
+		Name:        def.Name,
+		Description: def.Description,
+		Parameters:  def.Parameters,
+		Handler: func(ctx context.Context, arguments string) (string, error) {
+			return callWebhook(ctx, client, def, arguments)
+		},
+	}, nil
+}
+
+// callWebhook performs the HTTP call for a webhook tool and extracts the result
+func callWebhook(ctx context.Context, client *http.Client, def WebhookToolDefinition, arguments string) (string, error) {
+	args := make(map[string]interface{})
+	if arguments != "" {
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	// Reserved values come from the request, never from the model. The user_id is only as
+	// trustworthy as the caller, which is why generation requires the service token.
+	reserved := make(map[string]string)
+	if request, ok := RequestFromContext(ctx); ok && request.UserID != "" {
+		reserved["user_id"] = request.UserID
+	}
+
+	// Substitute the placeholders; the remaining arguments go in the query or body
+	used := make(map[string]bool)
+	target, err := fillPlaceholders(def.URL, args, reserved, used, url.PathEscape)
+	if err != nil {
+		return "", err
+	}
+	fields := make(map[string]interface{}, len(def.Body))
+	for key, value := range def.Body {
+		if template, ok := value.(string); ok {
+			if value, err = fillPlaceholders(template, args, reserved, used, func(s string) string { return s }); err != nil {
+				return "", err
+			}
+		}
+		fields[key] = value
+	}
+
+	var body io.Reader
+	switch def.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		// Template fields win over arguments of the same name
+		for name, value := range args {
+			if _, exists := fields[name]; !exists && !used[name] {
+				fields[name] = value
+			}
+		}
+		jsonBody, err := json.Marshal(fields)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(jsonBody)
+	default:
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL: %w", err)
+		}
+		// Query parameters in the URL template win over arguments of the same name
+		query := parsed.Query()
+		for name, value := range args {
+			if !used[name] && !query.Has(name) {
+				query.Set(name, fmt.Sprint(value))
+			}
+		}
+		parsed.RawQuery = query.Encode()
+		target = parsed.String()
+	}
+
+	// Create the request
+	req, err := http.NewRequestWithContext(ctx, def.Method, target, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range def.Headers {
+		req.Header.Set(key, os.ExpandEnv(value))
+	}
+
+	// Send the request
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s: %w", def.Name, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("endpoint returned status code %d: %s", resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	if len(def.ResponseFields) == 0 {
+		return truncate(string(respBody), maxWebhookResultLength), nil
+	}
+	return extractResponseFields(respBody, def.ResponseFields)
+}
+
+// fillPlaceholders replaces the placeholders in a template in a single pass, so values can't
+// introduce placeholders of their own. It fails when any placeholder has no value.
+func fillPlaceholders(template string, args map[string]interface{}, reserved map[string]string, used map[string]bool, escape func(string) string) (string, error) {
+	var missing string
+	filled := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := strings.Trim(placeholder, "{}")
+		if strings.HasPrefix(placeholder, "{{") {
+			if value, ok := reserved[name]; ok {
+				return escape(value)
+			}
+		} else if value, ok := args[name]; ok {
+			used[name] = true
+			return escape(fmt.Sprint(value))
+		}
+		if missing == "" {
+			missing = placeholder
+		}
+		return placeholder
+	})
+	if missing != "" {
+		return "", fmt.Errorf("no value for placeholder %s", missing)
+	}
+	return filled, nil
+}
+
+// extractResponseFields picks the configured fields out of a JSON response
+func extractResponseFields(body []byte, fields []string) (string, error) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// A single field is returned as its bare value
+	if len(fields) == 1 {
+		value, ok := lookupField(data, fields[0])
+		if !ok {
+			return "", fmt.Errorf("field %q not found in response", fields[0])
+		}
+		return truncate(formatValue(value), maxWebhookResultLength), nil
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := lookupField(data, field); ok {
+			result[field] = value
+		}
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return truncate(string(encoded), maxWebhookResultLength), nil
+}
+
+// lookupField follows a dotted path through decoded JSON
+func lookupField(data interface{}, path string) (interface{}, bool) {
+	current := data
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// formatValue renders a decoded JSON value as text
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// truncate shortens text to at most max characters
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "..."
+}
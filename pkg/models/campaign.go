@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Campaign statuses
+const (
+	CampaignDraft     = "draft"
+	CampaignScheduled = "scheduled"
+	CampaignRunning   = "running"
+	CampaignPaused    = "paused"
+	CampaignCompleted = "completed"
+	CampaignCancelled = "cancelled"
+)
+
+// Recipient statuses, in the order delivery progresses
+const (
+	RecipientPending   = "pending"
+	RecipientSent      = "sent"
+	RecipientDelivered = "delivered"
+	RecipientRead      = "read"
+	RecipientFailed    = "failed"
+	// RecipientSkipped marks recipients left unsent because the campaign was cancelled or they opted out
+	RecipientSkipped = "skipped"
+)
+
+// Campaign is a template message broadcast to a list of recipients
+type Campaign struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	Template OutboundTemplate `json:"template"`
+	Status   string           `json:"status"`
+	// StartAt is when sending begins; zero for drafts
+	StartAt     time.Time   `json:"start_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	CompletedAt time.Time   `json:"completed_at"`
+	Recipients  []Recipient `json:"recipients,omitempty"`
+	// Counts is the number of recipients in each status
+	Counts map[string]int `json:"counts"`
+}
+
+// Recipient is a campaign recipient with the values of its template parameters
+type Recipient struct {
+	Phone     string    `json:"phone"`
+	Variables []string  `json:"variables,omitempty"`
+	Status    string    `json:"status"`
+	MessageID string    `json:"message_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Attempts counts failed sends that will be retried
+	Attempts int `json:"attempts,omitempty"`
+	// NextAttemptAt is when a pending recipient may be retried after a temporary failure
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// CampaignRequest creates a campaign; it is scheduled when StartAt is set
+type CampaignRequest struct {
+	Name       string           `json:"name"`
+	Template   OutboundTemplate `json:"template"`
+	StartAt    *time.Time       `json:"start_at,omitempty"`
+	Recipients []Recipient      `json:"recipients,omitempty"`
+}
+
+// CampaignScheduleRequest schedules a campaign; a missing StartAt starts it now
+type CampaignScheduleRequest struct {
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
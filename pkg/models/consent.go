@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Consent statuses
+const (
+	ConsentOptedIn  = "opted_in"
+	ConsentOptedOut = "opted_out"
+)
+
+// Consent records whether a phone number agreed to receive messages
+type Consent struct {
+	Phone string `json:"phone"`
+	// Status is opted_in or opted_out
+	Status string `json:"status"`
+	// Source describes how consent was last given or withdrawn, e.g. keyword, api or a signup form
+	Source     string    `json:"source,omitempty"`
+	OptedInAt  time.Time `json:"opted_in_at"`
+	OptedOutAt time.Time `json:"opted_out_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ConsentRequest sets the consent of a phone number
+type ConsentRequest struct {
+	Status string `json:"status"`
+	Source string `json:"source"`
+}
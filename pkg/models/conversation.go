@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Conversation roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// ConversationTurn represents a single message exchanged in a conversation
+type ConversationTurn struct {
+	Role      string    `json:"role"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Conversation holds the remembered state of a conversation with a user
+type Conversation struct {
+	UserID    string             `json:"user_id"`
+	Summary   string             `json:"summary,omitempty"`
+	Turns     []ConversationTurn `json:"turns"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SharedDocument is a file a user sent in the chat, kept as context for their questions about it
+type SharedDocument struct {
+	Name string `json:"name"`
+	// Chunks hold the extracted text in pieces, so only the parts relevant to a question are
+	// put in the prompt
+	Chunks   []string  `json:"chunks"`
+	SharedAt time.Time `json:"shared_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Event types pushed to agents
+const (
+	EventMessage        = "message"
+	EventStatus         = "status"
+	EventHandoffStarted = "handoff.started"
+	EventHandoffEnded   = "handoff.ended"
+	EventHandoffQueued  = "handoff.queued"
+	// EventResync tells a reconnecting client that events were missed and its view must be reloaded
+	EventResync = "resync"
+)
+
+// Event is a real-time update about a conversation
+type Event struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	UserID    string    `json:"user_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Data is a ThreadMessage, MessageStatus or Handoff depending on the type
+	Data interface{} `json:"data,omitempty"`
+}
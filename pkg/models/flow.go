@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// FlowState is a user's progress through a flow, kept in their session between messages
+type FlowState struct {
+	// Name is the flow being filled in
+	Name string `json:"name"`
+	// Step is the ID of the step waiting for an answer
+	Step string `json:"step"`
+	// Answers are the validated answers by step ID
+	Answers   map[string]string `json:"answers"`
+	StartedAt time.Time         `json:"started_at"`
+}
+
+// FlowSummary describes a flow the assistant may start
+type FlowSummary struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// FlowResult is posted to the flow's webhook when a user completes it
+type FlowResult struct {
+	Flow        string            `json:"flow"`
+	UserID      string            `json:"user_id"`
+	Answers     map[string]string `json:"answers"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt time.Time         `json:"completed_at"`
+}
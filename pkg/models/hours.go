@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// BusinessHours describes whether the business's team is available
+type BusinessHours struct {
+	Open bool `json:"open"`
+	// OpensAt is when the business next opens, while it is closed
+	OpensAt *time.Time `json:"opens_at,omitempty"`
+	// ClosesAt is when the current opening period ends, while it is open
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+	// Schedule is the configured weekly schedule, e.g. "mon-fri 09:00-17:00"
+	Schedule string `json:"schedule,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// KnowledgeDocument describes a document in the knowledge base
+type KnowledgeDocument struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Format     string    `json:"format"`
+	Hash       string    `json:"hash"`
+	ChunkCount int       `json:"chunk_count"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// KnowledgeChunk is a passage of a knowledge document
+type KnowledgeChunk struct {
+	ID         string `json:"id"`
+	DocumentID string `json:"document_id"`
+	Source     string `json:"source"`
+	Text       string `json:"text"`
+}
+
+// RetrievedChunk is a knowledge chunk matched to a query
+type RetrievedChunk struct {
+	KnowledgeChunk
+	Score float64 `json:"score"`
+}
+
+// KnowledgeQueryRequest is a test query against the knowledge base
+type KnowledgeQueryRequest struct {
+	Query string `json:"query"`
+	TopK  int    `json:"top_k,omitempty"`
+}
+
+// KnowledgeQueryResponse shows which passages a query retrieves and how they score
+type KnowledgeQueryResponse struct {
+	Query    string           `json:"query"`
+	MinScore float64          `json:"min_score"`
+	Results  []RetrievedChunk `json:"results"`
+}
+
+// ReindexStatus describes the state of knowledge base reindexing
+type ReindexStatus struct {
+	Running    bool      `json:"running"`
+	Force      bool      `json:"force"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
@@ -4,10 +4,32 @@ import "time"
 
 // Message represents a WhatsApp message
 type Message struct {
-	ID        string    `json:"id"`
-	From      string    `json:"from"`
-	Text      string    `json:"text"`
-	Timestamp time.Time `json:"timestamp"`
+	ID   string `json:"id"`
+	From string `json:"from"`
+	Text string `json:"text"`
+	// ReplyID is the ID of the button or list item the user tapped, if any
+	ReplyID string `json:"reply_id,omitempty"`
+	// Media is the file attached to the message, if any
+	Media     *MessageMedia `json:"media,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// Media types of inbound messages
+const (
+	MediaAudio    = "audio"
+	MediaImage    = "image"
+	MediaDocument = "document"
+)
+
+// MessageMedia identifies a file a user sent, which can be downloaded by its ID
+type MessageMedia struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	MIMEType string `json:"mime_type,omitempty"`
+	// Filename is the name of a document as the user sent it
+	Filename string `json:"filename,omitempty"`
+	// Voice is set for voice notes recorded in WhatsApp
+	Voice bool `json:"voice,omitempty"`
 }
 
 // LLMRequest represents a request to the LLM service
@@ -15,12 +37,59 @@ type LLMRequest struct {
 	UserID      string   `json:"user_id"`
 	MessageText string   `json:"message_text"`
 	History     []string `json:"history,omitempty"`
+	// Model optionally overrides the configured model, as "provider:model" or a bare model name
+	Model string `json:"model,omitempty"`
+	// Language is the code of the language the reply must be written in: the one the user chose,
+	// the one they write in, or the default
+	Language string `json:"language,omitempty"`
+	// Timezone is the user's IANA timezone name, used for dates and times the user mentions
+	Timezone string `json:"timezone,omitempty"`
+	// BusinessHours tells the assistant whether the team is available, when business hours are configured
+	BusinessHours *BusinessHours `json:"business_hours,omitempty"`
+	// Flows are the forms the assistant may start for the user with the start_flow tool
+	Flows []FlowSummary `json:"flows,omitempty"`
+	// Attachments are files sent with the message, such as a photo whose caption is the message text
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Documents are the files the user shared earlier in the chat, to answer questions about
+	Documents []SharedDocument `json:"documents,omitempty"`
+
+	// Optional generation parameters overriding the deployment defaults
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// Attachment is a file the user sent with their message
+type Attachment struct {
+	// Type is the media type, e.g. image
+	Type     string `json:"type"`
+	MIMEType string `json:"mime_type"`
+	// Data is the file content, base64-encoded in JSON
+	Data []byte `json:"data"`
 }
 
 // LLMResponse represents a response from the LLM service
 type LLMResponse struct {
-	ResponseText string `json:"response_text"`
-	Error        string `json:"error,omitempty"`
+	ResponseText string     `json:"response_text"`
+	Provider     string     `json:"provider,omitempty"`
+	Model        string     `json:"model,omitempty"`
+	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// ToolCall records a tool executed on the model's request
+type ToolCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// LLMStreamChunk represents a chunk of a streamed LLM response
+type LLMStreamChunk struct {
+	Text string `json:"text"`
 }
 
 // WhatsAppWebhookRequest represents the incoming webhook request from WhatsApp
@@ -48,24 +117,178 @@ type WhatsAppWebhookRequest struct {
 					Text      struct {
 						Body string `json:"body"`
 					} `json:"text"`
-					Type string `json:"type"`
+					Interactive struct {
+						Type        string              `json:"type"`
+						ButtonReply WhatsAppButtonReply `json:"button_reply"`
+						ListReply   WhatsAppButtonReply `json:"list_reply"`
+					} `json:"interactive"`
+					Button struct {
+						Payload string `json:"payload"`
+						Text    string `json:"text"`
+					} `json:"button"`
+					Audio    WhatsAppInboundMedia `json:"audio"`
+					Image    WhatsAppInboundMedia `json:"image"`
+					Document WhatsAppInboundMedia `json:"document"`
+					Type     string               `json:"type"`
 				} `json:"messages"`
+				Statuses []struct {
+					ID          string `json:"id"`
+					Status      string `json:"status"`
+					Timestamp   string `json:"timestamp"`
+					RecipientID string `json:"recipient_id"`
+					Errors      []struct {
+						Code  int    `json:"code"`
+						Title string `json:"title"`
+					} `json:"errors"`
+				} `json:"statuses"`
 			} `json:"value"`
 			Field string `json:"field"`
 		} `json:"changes"`
 	} `json:"entry"`
 }
 
+// WhatsAppInboundMedia is a file attached to an incoming message
+type WhatsAppInboundMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	SHA256   string `json:"sha256"`
+	Caption  string `json:"caption"`
+	Filename string `json:"filename"`
+	Voice    bool   `json:"voice"`
+}
+
+// WhatsAppMediaInfo describes a media file and its temporary download URL
+type WhatsAppMediaInfo struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	FileSize int64  `json:"file_size"`
+}
+
+// MessageStatus is a delivery status update for a message sent to a user
+type MessageStatus struct {
+	MessageID   string `json:"message_id"`
+	RecipientID string `json:"recipient_id"`
+	// Status is one of sent, delivered, read or failed
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
 // WhatsAppSendMessageRequest represents the request to send a message via WhatsApp
 type WhatsAppSendMessageRequest struct {
-	MessagingProduct string `json:"messaging_product"`
-	RecipientType    string `json:"recipient_type"`
-	To               string `json:"to"`
-	Type             string `json:"type"`
-	Text             struct {
-		PreviewURL bool   `json:"preview_url"`
-		Body       string `json:"body"`
-	} `json:"text"`
+	MessagingProduct string               `json:"messaging_product"`
+	RecipientType    string               `json:"recipient_type"`
+	To               string               `json:"to"`
+	Type             string               `json:"type"`
+	Text             *WhatsAppText        `json:"text,omitempty"`
+	Interactive      *WhatsAppInteractive `json:"interactive,omitempty"`
+	Template         *WhatsAppTemplate    `json:"template,omitempty"`
+	Image            *WhatsAppMedia       `json:"image,omitempty"`
+	Document         *WhatsAppMedia       `json:"document,omitempty"`
+	Audio            *WhatsAppMedia       `json:"audio,omitempty"`
+	Video            *WhatsAppMedia       `json:"video,omitempty"`
+}
+
+// WhatsAppText is the content of a text message
+type WhatsAppText struct {
+	PreviewURL bool   `json:"preview_url"`
+	Body       string `json:"body"`
+}
+
+// WhatsAppInteractive is the content of an interactive message
+type WhatsAppInteractive struct {
+	Type string `json:"type"`
+	Body struct {
+		Text string `json:"text"`
+	} `json:"body"`
+	Action struct {
+		Buttons []WhatsAppButton `json:"buttons,omitempty"`
+		// Button is the label of the button that opens a list message
+		Button   string                `json:"button,omitempty"`
+		Sections []WhatsAppListSection `json:"sections,omitempty"`
+	} `json:"action"`
+}
+
+// WhatsAppListSection is a titled group of rows in a list message
+type WhatsAppListSection struct {
+	Title string            `json:"title,omitempty"`
+	Rows  []WhatsAppListRow `json:"rows"`
+}
+
+// WhatsAppListRow is a selectable row of a list message
+type WhatsAppListRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// WhatsAppMedia is the content of an image, document, audio or video message.
+// The media is either a previously uploaded media ID or a public link.
+type WhatsAppMedia struct {
+	ID       string `json:"id,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// WhatsAppButton is a reply button of an interactive message
+type WhatsAppButton struct {
+	Type  string              `json:"type"`
+	Reply WhatsAppButtonReply `json:"reply"`
+}
+
+// WhatsAppButtonReply identifies a reply button and its label
+type WhatsAppButtonReply struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// WhatsAppTemplate is the content of a template message
+type WhatsAppTemplate struct {
+	Name     string `json:"name"`
+	Language struct {
+		Code string `json:"code"`
+	} `json:"language"`
+	Components []WhatsAppTemplateComponent `json:"components,omitempty"`
+}
+
+// WhatsAppTemplateComponent fills in the parameters of a template component
+type WhatsAppTemplateComponent struct {
+	Type       string                      `json:"type"`
+	Parameters []WhatsAppTemplateParameter `json:"parameters"`
+}
+
+// WhatsAppTemplateParameter is a value substituted into a template
+type WhatsAppTemplateParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// OutboundMessageRequest is a message sent to a user on behalf of another system
+type OutboundMessageRequest struct {
+	To string `json:"to"`
+	// Type is one of text, template, image, document, audio, video or interactive
+	Type        string               `json:"type"`
+	Text        string               `json:"text,omitempty"`
+	Template    *OutboundTemplate    `json:"template,omitempty"`
+	Media       *WhatsAppMedia       `json:"media,omitempty"`
+	Interactive *WhatsAppInteractive `json:"interactive,omitempty"`
+}
+
+// OutboundTemplate names a pre-approved template and its body parameters
+type OutboundTemplate struct {
+	Name       string   `json:"name"`
+	Language   string   `json:"language"`
+	Parameters []string `json:"parameters,omitempty"`
+}
+
+// OutboundMessageResponse identifies a sent message
+type OutboundMessageResponse struct {
+	MessageID string `json:"message_id"`
+	To        string `json:"to"`
+	// FallbackTemplate is set when a text message was sent as this template because the service window was closed
+	FallbackTemplate string `json:"fallback_template,omitempty"`
 }
 
 // WhatsAppSendMessageResponse represents the response from WhatsApp when sending a message
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Scheduled message statuses
+const (
+	SchedulePending   = "pending"
+	ScheduleSent      = "sent"
+	ScheduleFailed    = "failed"
+	ScheduleCancelled = "cancelled"
+)
+
+// Scheduled message sources
+const (
+	ScheduleSourceAPI       = "api"
+	ScheduleSourceAssistant = "assistant"
+)
+
+// ScheduledMessage is a text message sent to a user at a set time, such as a reminder
+type ScheduledMessage struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Text   string `json:"text"`
+	// SendAt is when the message is sent, in the timezone it was scheduled in
+	SendAt   time.Time `json:"send_at"`
+	Timezone string    `json:"timezone"`
+	Status   string    `json:"status"`
+	// Source is api or assistant
+	Source    string    `json:"source,omitempty"`
+	MessageID string    `json:"message_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ScheduleRequest schedules a message
+type ScheduleRequest struct {
+	UserID string `json:"user_id"`
+	Text   string `json:"text"`
+	// SendAt is an RFC 3339 time, or a local time such as 2024-06-01T09:00 in the timezone
+	SendAt string `json:"send_at"`
+	// Timezone is an IANA timezone name; defaults to the user's timezone
+	Timezone string `json:"timezone,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import "time"
+
+// Session modes
+const (
+	// SessionModeBot lets the assistant answer automatically
+	SessionModeBot = "bot"
+	// SessionModeHuman pauses automatic replies while an agent handles the conversation
+	SessionModeHuman = "human"
+	// SessionModeQueued marks a handoff requested outside business hours; the assistant keeps
+	// answering until the business opens and an agent takes over
+	SessionModeQueued = "queued"
+	// SessionModeClosed marks a conversation closed by an agent; the next message reopens it with the bot
+	SessionModeClosed = "closed"
+)
+
+// Reply modes
+const (
+	// ReplyModeText answers in text
+	ReplyModeText = "text"
+	// ReplyModeVoice answers with voice notes
+	ReplyModeVoice = "voice"
+	// ReplyModeAuto answers voice notes with voice notes and text with text
+	ReplyModeAuto = "auto"
+)
+
+// Thread message senders
+const (
+	SenderUser  = "user"
+	SenderBot   = "bot"
+	SenderAgent = "agent"
+	// SenderSystem is another system sending through the outbound API
+	SenderSystem = "system"
+)
+
+// Session holds the WhatsApp service's state for a user
+type Session struct {
+	UserID string `json:"user_id"`
+	// Language is the reply language code the user chose; empty means the detected language
+	Language string `json:"language,omitempty"`
+	// DetectedLanguage is the language code the user last wrote in; empty means the default
+	DetectedLanguage string `json:"detected_language,omitempty"`
+	// Timezone is the user's IANA timezone name; empty means the default
+	Timezone string `json:"timezone,omitempty"`
+	// ReplyMode is one of text, voice or auto; empty means the default
+	ReplyMode string `json:"reply_mode,omitempty"`
+
+	// Mode is one of bot, human, queued or closed; empty means bot
+	Mode          string    `json:"mode,omitempty"`
+	HandoffReason string    `json:"handoff_reason,omitempty"`
+	HandoffAt     time.Time `json:"handoff_at"`
+	// Failures counts consecutive messages the bot failed to answer
+	Failures int `json:"failures,omitempty"`
+	// ClosedNoticeUntil is the opening time the user was last told about, so the out-of-hours
+	// notice is sent once per closed period
+	ClosedNoticeUntil time.Time `json:"closed_notice_until"`
+	// Flow is the form the user is filling in, if any
+	Flow *FlowState `json:"flow,omitempty"`
+	// Documents are the files the user shared, kept as context until they expire
+	Documents []SharedDocument `json:"documents,omitempty"`
+
+	// Thread holds the most recent messages exchanged with the user
+	Thread        []ThreadMessage `json:"thread,omitempty"`
+	LastMessageAt time.Time       `json:"last_message_at"`
+	// LastInboundAt is when the user last sent a message, which opens the 24-hour service window
+	LastInboundAt time.Time `json:"last_inbound_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ServiceWindow describes a user's 24-hour customer service window, during which free-form
+// messages may be sent
+type ServiceWindow struct {
+	UserID        string     `json:"user_id"`
+	Open          bool       `json:"open"`
+	LastInboundAt *time.Time `json:"last_inbound_at,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// ThreadMessage is a message in a user's thread
+type ThreadMessage struct {
+	// Sender is one of user, bot or agent
+	Sender    string    `json:"sender"`
+	AgentID   string    `json:"agent_id,omitempty"`
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Handoff summarizes a conversation waiting for or handled by an agent
+type Handoff struct {
+	UserID        string    `json:"user_id"`
+	Mode          string    `json:"mode"`
+	Reason        string    `json:"reason,omitempty"`
+	Since         time.Time `json:"since"`
+	LastMessage   string    `json:"last_message,omitempty"`
+	LastMessageAt time.Time `json:"last_message_at"`
+}
+
+// AgentReplyRequest is a message sent to a user by an agent
+type AgentReplyRequest struct {
+	AgentID string `json:"agent_id"`
+	Text    string `json:"text"`
+}
@@ -0,0 +1,269 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Scheduler errors
+var (
+	ErrNotFound   = errors.New("scheduled message not found")
+	ErrNotPending = errors.New("scheduled message was already sent or cancelled")
+	ErrInvalid    = errors.New("invalid scheduled message")
+)
+
+// tickInterval is how often the scheduler looks for messages that are due
+const tickInterval = time.Second
+
+// pruneInterval is how often finished messages past the retention are removed
+const pruneInterval = time.Hour
+
+// localLayouts are the accepted formats of times without a UTC offset
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// SendFunc sends a scheduled message and returns its WhatsApp message ID
+type SendFunc func(ctx context.Context, msg *models.ScheduledMessage) (string, error)
+
+// Scheduler sends messages at their scheduled time. Pending messages are kept in the store,
+// so they are still sent after a restart.
+type Scheduler struct {
+	store Store
+	send  SendFunc
+	// retention is how long finished messages are kept; 0 keeps them forever
+	retention time.Duration
+
+	mu       sync.Mutex
+	messages map[string]*models.ScheduledMessage
+	// sending holds the messages being sent, which can no longer be cancelled
+	sending map[string]bool
+}
+
+// NewScheduler creates a scheduler and loads the stored messages. Sent, failed and cancelled
+// messages are removed once they are older than retention, unless it is 0.
+func NewScheduler(ctx context.Context, store Store, retention time.Duration, send SendFunc) (*Scheduler, error) {
+	messages, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scheduled messages: %w", err)
+	}
+
+	s := &Scheduler{
+		store:     store,
+		send:      send,
+		retention: retention,
+		messages:  make(map[string]*models.ScheduledMessage, len(messages)),
+		sending:   make(map[string]bool),
+	}
+	for _, msg := range messages {
+		s.messages[msg.ID] = msg
+	}
+	return s, nil
+}
+
+// Schedule stores a message to be sent to a user at the given time
+func (s *Scheduler) Schedule(ctx context.Context, userID, text string, sendAt time.Time, loc *time.Location, source string) (*models.ScheduledMessage, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalid)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("%w: text is required", ErrInvalid)
+	}
+	if !sendAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: send_at must be in the future", ErrInvalid)
+	}
+
+	now := time.Now()
+	msg := &models.ScheduledMessage{
+		ID:        newID(),
+		UserID:    userID,
+		Text:      text,
+		SendAt:    sendAt.In(loc),
+		Timezone:  loc.String(),
+		Status:    models.SchedulePending,
+		Source:    source,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Save(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.messages[msg.ID] = msg
+	result := *msg
+	s.mu.Unlock()
+
+	log.Printf("Scheduled message %s for %s at %s", msg.ID, userID, msg.SendAt.Format(time.RFC3339))
+	return &result, nil
+}
+
+// Cancel cancels a pending message. When userID is set, the message must belong to that user.
+func (s *Scheduler) Cancel(ctx context.Context, id, userID string) (*models.ScheduledMessage, error) {
+	s.mu.Lock()
+	msg, ok := s.messages[id]
+	if !ok || (userID != "" && msg.UserID != userID) {
+		s.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if msg.Status != models.SchedulePending || s.sending[id] {
+		s.mu.Unlock()
+		return nil, ErrNotPending
+	}
+	msg.Status = models.ScheduleCancelled
+	msg.UpdatedAt = time.Now()
+	result := *msg
+	s.mu.Unlock()
+
+	return &result, s.store.Save(ctx, &result)
+}
+
+// Get returns a copy of a scheduled message
+func (s *Scheduler) Get(id string) (*models.ScheduledMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages[id]
+	if !ok {
+		return nil, false
+	}
+	result := *msg
+	return &result, true
+}
+
+// List returns copies of the scheduled messages, optionally only a user's or those in a status,
+// ordered by send time
+func (s *Scheduler) List(userID, status string) []*models.ScheduledMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []*models.ScheduledMessage
+	for _, msg := range s.messages {
+		if (userID == "" || msg.UserID == userID) && (status == "" || msg.Status == status) {
+			result := *msg
+			messages = append(messages, &result)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].SendAt.Before(messages[j].SendAt) })
+	return messages
+}
+
+// Run sends messages as they become due until ctx is cancelled. Messages that became due
+// while the service was down are sent on the first tick.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	s.prune(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, msg := range s.due(time.Now()) {
+				messageID, err := s.send(ctx, msg)
+				s.recordSend(ctx, msg.ID, messageID, err)
+			}
+		case <-pruneTicker.C:
+			s.prune(ctx, time.Now())
+		}
+	}
+}
+
+// prune removes the sent, failed and cancelled messages last updated before the retention
+func (s *Scheduler) prune(ctx context.Context, now time.Time) {
+	if s.retention <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	var expired []string
+	for id, msg := range s.messages {
+		if msg.Status != models.SchedulePending && !s.sending[id] && now.Sub(msg.UpdatedAt) > s.retention {
+			expired = append(expired, id)
+			delete(s.messages, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range expired {
+		if err := s.store.Delete(ctx, id); err != nil {
+			log.Printf("Failed to delete scheduled message %s: %v", id, err)
+		}
+	}
+}
+
+// due picks the pending messages whose time has come and marks them as being sent
+func (s *Scheduler) due(now time.Time) []*models.ScheduledMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*models.ScheduledMessage
+	for id, msg := range s.messages {
+		if msg.Status == models.SchedulePending && !msg.SendAt.After(now) && !s.sending[id] {
+			s.sending[id] = true
+			result := *msg
+			due = append(due, &result)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].SendAt.Before(due[j].SendAt) })
+	return due
+}
+
+// recordSend stores the outcome of sending a scheduled message
+func (s *Scheduler) recordSend(ctx context.Context, id, messageID string, err error) {
+	s.mu.Lock()
+	msg := s.messages[id]
+	delete(s.sending, id)
+	if err != nil {
+		msg.Status = models.ScheduleFailed
+		msg.Error = err.Error()
+		log.Printf("Failed to send scheduled message %s to %s: %v", id, msg.UserID, err)
+	} else {
+		msg.Status = models.ScheduleSent
+		msg.MessageID = messageID
+	}
+	msg.UpdatedAt = time.Now()
+	result := *msg
+	s.mu.Unlock()
+
+	if err := s.store.Save(ctx, &result); err != nil {
+		log.Printf("Failed to save scheduled message %s: %v", id, err)
+	}
+}
+
+// ParseTime parses an RFC 3339 time, or a local time without a UTC offset in loc
+func ParseTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: send_at %q must look like 2006-01-02T15:04 or be an RFC 3339 time", ErrInvalid, value)
+}
+
+// newID returns a random scheduled message ID
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
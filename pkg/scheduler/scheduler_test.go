@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+func TestPruneRemovesFinishedMessages(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	messages := []*models.ScheduledMessage{
+		{ID: "old-sent", Status: models.ScheduleSent, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "old-failed", Status: models.ScheduleFailed, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "old-cancelled", Status: models.ScheduleCancelled, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "new-sent", Status: models.ScheduleSent, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "old-pending", Status: models.SchedulePending, SendAt: now.Add(time.Hour), UpdatedAt: now.Add(-48 * time.Hour)},
+	}
+	for _, msg := range messages {
+		if err := store.Save(ctx, msg); err != nil {
+			t.Fatalf("Save(%s) error = %v", msg.ID, err)
+		}
+	}
+
+	s, err := NewScheduler(ctx, store, 24*time.Hour, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	s.prune(ctx, now)
+
+	want := map[string]bool{"new-sent": true, "old-pending": true}
+	for _, msg := range messages {
+		if _, ok := s.Get(msg.ID); ok != want[msg.ID] {
+			t.Errorf("Get(%s) found = %v, want %v", msg.ID, ok, want[msg.ID])
+		}
+	}
+
+	// The pruned messages are gone from the store too
+	stored, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(stored) != len(want) {
+		t.Errorf("store has %d messages after prune, want %d", len(stored), len(want))
+	}
+	for _, msg := range stored {
+		if !want[msg.ID] {
+			t.Errorf("store still has %s", msg.ID)
+		}
+	}
+}
+
+func TestPruneWithoutRetention(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if err := store.Save(ctx, &models.ScheduledMessage{ID: "sent", Status: models.ScheduleSent}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s, err := NewScheduler(ctx, store, 0, nil)
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	s.prune(ctx, time.Now())
+
+	if _, ok := s.Get("sent"); !ok {
+		t.Error("prune() without a retention removed a sent message")
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Store persists scheduled messages
+type Store interface {
+	// Save stores the scheduled message
+	Save(ctx context.Context, msg *models.ScheduledMessage) error
+	// List returns every stored scheduled message
+	List(ctx context.Context) ([]*models.ScheduledMessage, error)
+	// Delete removes a scheduled message
+	Delete(ctx context.Context, id string) error
+}
+
+// NewStore returns a file store in SCHEDULER_STORE_DIR, or an in-memory store when it is unset
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.SchedulerStoreDir == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewFileStore(cfg.SchedulerStoreDir)
+}
+
+// MemoryStore keeps scheduled messages in process memory
+type MemoryStore struct {
+	mu       sync.RWMutex
+	messages map[string]models.ScheduledMessage
+}
+
+// NewMemoryStore creates a new in-memory scheduled message store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		messages: make(map[string]models.ScheduledMessage),
+	}
+}
+
+// Save stores a copy of the scheduled message
+func (s *MemoryStore) Save(ctx context.Context, msg *models.ScheduledMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages[msg.ID] = *msg
+	return nil
+}
+
+// List returns copies of every stored scheduled message
+func (s *MemoryStore) List(ctx context.Context) ([]*models.ScheduledMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := make([]*models.ScheduledMessage, 0, len(s.messages))
+	for _, msg := range s.messages {
+		m := msg
+		messages = append(messages, &m)
+	}
+	return messages, nil
+}
+
+// Delete removes a scheduled message
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.messages, id)
+	return nil
+}
+
+// FileStore keeps each scheduled message as a JSON file in a directory
+type FileStore struct {
+	dir *jsonfile.Dir
+}
+
+// NewFileStore creates a new file-backed scheduled message store
+func NewFileStore(dir string) (*FileStore, error) {
+	d, err := jsonfile.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler store directory: %w", err)
+	}
+	return &FileStore{dir: d}, nil
+}
+
+// Save writes the scheduled message file atomically
+func (s *FileStore) Save(ctx context.Context, msg *models.ScheduledMessage) error {
+	if err := s.dir.Put(msg.ID, msg); err != nil {
+		return fmt.Errorf("failed to write scheduled message: %w", err)
+	}
+	return nil
+}
+
+// List reads every scheduled message file in the directory
+func (s *FileStore) List(ctx context.Context) ([]*models.ScheduledMessage, error) {
+	messages, err := jsonfile.List[models.ScheduledMessage](s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled messages: %w", err)
+	}
+	return messages, nil
+}
+
+// Delete removes the scheduled message file
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if err := s.dir.Delete(id); err != nil {
+		return fmt.Errorf("failed to delete scheduled message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/jsonfile"
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// Store persists sessions keyed by user ID
+type Store interface {
+	// Get returns the session for a user, or an empty one if none exists
+	Get(ctx context.Context, userID string) (*models.Session, error)
+	// Save stores the session
+	Save(ctx context.Context, sess *models.Session) error
+	// Delete removes the session for a user
+	Delete(ctx context.Context, userID string) error
+	// List returns every stored session
+	List(ctx context.Context) ([]*models.Session, error)
+}
+
+// NewStore returns a file store in SESSION_STORE_DIR, or an in-memory store when it is unset
+func NewStore(cfg *config.Config) (Store, error) {
+	if cfg.SessionStoreDir == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewFileStore(cfg.SessionStoreDir)
+}
+
+// MemoryStore keeps sessions in process memory
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*models.Session
+}
+
+// NewMemoryStore creates a new in-memory session store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*models.Session),
+	}
+}
+
+// Get returns a copy of the stored session
+func (s *MemoryStore) Get(ctx context.Context, userID string) (*models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sess, ok := s.sessions[userID]
+	if !ok {
+		return &models.Session{UserID: userID}, nil
+	}
+	return copySession(sess), nil
+}
+
+// Save stores a copy of the session
+func (s *MemoryStore) Save(ctx context.Context, sess *models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[sess.UserID] = copySession(sess)
+	return nil
+}
+
+// Delete removes the session for a user
+func (s *MemoryStore) Delete(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, userID)
+	return nil
+}
+
+// List returns copies of every stored session
+func (s *MemoryStore) List(ctx context.Context) ([]*models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]*models.Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, copySession(sess))
+	}
+	return sessions, nil
+}
+
+// FileStore keeps each session as a JSON file in a directory
+type FileStore struct {
+	dir *jsonfile.Dir
+}
+
+// NewFileStore creates a new file-backed session store
+func NewFileStore(dir string) (*FileStore, error) {
+	d, err := jsonfile.NewDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session store directory: %w", err)
+	}
+	return &FileStore{dir: d}, nil
+}
+
+// Get reads the session file for a user
+func (s *FileStore) Get(ctx context.Context, userID string) (*models.Session, error) {
+	var sess models.Session
+	found, err := s.dir.Get(userID, &sess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+	if !found {
+		return &models.Session{UserID: userID}, nil
+	}
+	return &sess, nil
+}
+
+// Save writes the session file atomically
+func (s *FileStore) Save(ctx context.Context, sess *models.Session) error {
+	if err := s.dir.Put(sess.UserID, sess); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the session file for a user
+func (s *FileStore) Delete(ctx context.Context, userID string) error {
+	if err := s.dir.Delete(userID); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// List reads every session file in the directory
+func (s *FileStore) List(ctx context.Context) ([]*models.Session, error) {
+	sessions, err := jsonfile.List[models.Session](s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// copySession returns a deep copy so callers cannot mutate stored state
+func copySession(sess *models.Session) *models.Session {
+	c := *sess
+	c.Thread = append([]models.ThreadMessage(nil), sess.Thread...)
+	return &c
+}
@@ -0,0 +1,87 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Command placeholders
+const (
+	placeholderInput    = "{input}"
+	placeholderLanguage = "{language}"
+)
+
+// maxStderr limits how much of a failed command's error output is reported
+const maxStderr = 500
+
+// Command transcribes audio with a local program, e.g. a script that converts the audio with
+// ffmpeg and runs whisper.cpp. The program prints the transcript on standard output.
+type Command struct {
+	args     []string
+	language string
+	timeout  time.Duration
+}
+
+// NewCommand creates a transcriber running the command line, split on spaces. {input} is
+// replaced by the path of the audio file, which is appended when the placeholder is missing,
+// and {language} by the language hint.
+func NewCommand(commandLine, language string, timeout time.Duration) (*Command, error) {
+	args := strings.Fields(commandLine)
+	if len(args) == 0 {
+		return nil, errors.New("transcription command is required")
+	}
+	if !strings.Contains(commandLine, placeholderInput) {
+		args = append(args, placeholderInput)
+	}
+	return &Command{
+		args:     args,
+		language: language,
+		timeout:  timeout,
+	}, nil
+}
+
+// Transcribe writes the audio to a temporary file and runs the command on it
+func (c *Command) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	file, err := os.CreateTemp("", "voice-*"+extension(mimeType))
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(audio)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	// Fill in the placeholders
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		arg = strings.ReplaceAll(arg, placeholderInput, file.Name())
+		args[i] = strings.ReplaceAll(arg, placeholderLanguage, c.language)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxStderr {
+			output = output[len(output)-maxStderr:]
+		}
+		return "", fmt.Errorf("transcription command failed: %w: %s", err, output)
+	}
+	return clean(stdout.String())
+}
@@ -0,0 +1,89 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAI transcribes audio with an OpenAI-compatible /audio/transcriptions endpoint, such as
+// OpenAI Whisper, Groq or a self-hosted faster-whisper server
+type OpenAI struct {
+	baseURL  string
+	apiKey   string
+	model    string
+	language string
+	client   *http.Client
+}
+
+// NewOpenAI creates a transcriber for the endpoint at baseURL; language is an optional ISO-639-1 hint
+func NewOpenAI(baseURL, apiKey, model, language string, timeout time.Duration) *OpenAI {
+	return &OpenAI{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		language: language,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Transcribe uploads the audio and returns the recognized text
+func (o *OpenAI) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	// Build the multipart form
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "audio"+extension(mimeType))
+	if err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+	if _, err := file.Write(audio); err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+	form.WriteField("model", o.model)
+	form.WriteField("response_format", "json")
+	if o.language != "" {
+		form.WriteField("language", o.language)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+
+	// Create the request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	// Send the request
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call transcription endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("transcription endpoint returned status code %d: %s", resp.StatusCode, respBody)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal transcription: %w", err)
+	}
+	return clean(result.Text)
+}
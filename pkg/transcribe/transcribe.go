@@ -0,0 +1,77 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+)
+
+// Transcription providers
+const (
+	// ProviderOpenAI posts audio to an OpenAI-compatible /audio/transcriptions endpoint
+	ProviderOpenAI = "openai"
+	// ProviderCommand runs a local speech-to-text program such as whisper.cpp
+	ProviderCommand = "command"
+)
+
+// ErrNoSpeech is returned when the audio contains no recognizable speech
+var ErrNoSpeech = errors.New("no speech recognized")
+
+// Transcriber turns recorded speech into text
+type Transcriber interface {
+	// Transcribe returns the text spoken in the audio, which has the given MIME type
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+}
+
+// New creates the configured transcriber, or returns nil when transcription is disabled
+func New(cfg *config.Config) (Transcriber, error) {
+	timeout := time.Duration(cfg.TranscribeTimeout) * time.Second
+	switch cfg.TranscribeProvider {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		return NewOpenAI(cfg.TranscribeBaseURL, cfg.TranscribeAPIKey, cfg.TranscribeModel, cfg.TranscribeLanguage, timeout), nil
+	case ProviderCommand:
+		return NewCommand(cfg.TranscribeCommand, cfg.TranscribeLanguage, timeout)
+	default:
+		return nil, fmt.Errorf("unknown transcription provider %q", cfg.TranscribeProvider)
+	}
+}
+
+// extension returns a file extension for the audio's MIME type, which backends use to detect
+// the format. WhatsApp voice notes are Ogg Opus.
+func extension(mimeType string) string {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	switch mediaType {
+	case "audio/ogg", "audio/opus":
+		return ".ogg"
+	case "audio/mpeg":
+		return ".mp3"
+	case "audio/mp4", "audio/aac", "audio/x-m4a":
+		return ".m4a"
+	case "audio/amr":
+		return ".amr"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return ".wav"
+	case "audio/webm":
+		return ".webm"
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".ogg"
+}
+
+// clean trims a transcript and reports silence as ErrNoSpeech
+func clean(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrNoSpeech
+	}
+	return text, nil
+}
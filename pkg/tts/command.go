@@ -0,0 +1,63 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// placeholderLanguage is replaced by the language hint in the command line
+const placeholderLanguage = "{language}"
+
+// maxStderr limits how much of a failed command's error output is reported
+const maxStderr = 500
+
+// Command synthesizes speech with a local program, e.g. a script that runs Piper and encodes
+// its output with ffmpeg. The program reads the text on standard input and writes Ogg Opus
+// audio on standard output.
+type Command struct {
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommand creates a synthesizer running the command line, split on spaces. {language} is
+// replaced by the language of the reply.
+func NewCommand(commandLine string, timeout time.Duration) (*Command, error) {
+	args := strings.Fields(commandLine)
+	if len(args) == 0 {
+		return nil, errors.New("speech synthesis command is required")
+	}
+	return &Command{args: args, timeout: timeout}, nil
+}
+
+// Synthesize runs the command with the text on its input and returns the audio it prints
+func (c *Command) Synthesize(ctx context.Context, text, language string) ([]byte, error) {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = strings.ReplaceAll(arg, placeholderLanguage, language)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxStderr {
+			output = output[len(output)-maxStderr:]
+		}
+		return nil, fmt.Errorf("speech synthesis command failed: %w: %s", err, output)
+	}
+	if stdout.Len() == 0 {
+		return nil, errors.New("speech synthesis command printed no audio")
+	}
+	return stdout.Bytes(), nil
+}
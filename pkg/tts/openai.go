@@ -0,0 +1,86 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxAudioSize limits the size of synthesized audio read from the endpoint
+const maxAudioSize = 16 << 20
+
+// OpenAI synthesizes speech with an OpenAI-compatible /audio/speech endpoint, such as OpenAI
+// or a self-hosted server like openedai-speech
+type OpenAI struct {
+	baseURL string
+	apiKey  string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+// NewOpenAI creates a synthesizer for the endpoint at baseURL speaking with the given voice
+func NewOpenAI(baseURL, apiKey, model, voice string, timeout time.Duration) *OpenAI {
+	return &OpenAI{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		voice:   voice,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Synthesize sends the text and returns the spoken audio. The endpoint detects the language
+// itself, so the hint is unused.
+func (o *OpenAI) Synthesize(ctx context.Context, text, language string) ([]byte, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":           o.model,
+		"input":           text,
+		"voice":           o.voice,
+		"response_format": "opus",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	// Create the request
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	// Send the request
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call speech endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read speech: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		if len(audio) > 1024 {
+			audio = audio[:1024]
+		}
+		return nil, fmt.Errorf("speech endpoint returned status code %d: %s", resp.StatusCode, audio)
+	}
+	if len(audio) > maxAudioSize {
+		return nil, errors.New("speech endpoint returned too much audio")
+	}
+	if len(audio) == 0 {
+		return nil, errors.New("speech endpoint returned no audio")
+	}
+	return audio, nil
+}
@@ -0,0 +1,41 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
+)
+
+// Speech synthesis providers
+const (
+	// ProviderOpenAI posts text to an OpenAI-compatible /audio/speech endpoint
+	ProviderOpenAI = "openai"
+	// ProviderCommand runs a local text-to-speech program such as Piper
+	ProviderCommand = "command"
+)
+
+// MIMEType is the type of the audio synthesizers produce: Ogg Opus, which WhatsApp plays as a voice note
+const MIMEType = "audio/ogg"
+
+// Synthesizer turns text into speech
+type Synthesizer interface {
+	// Synthesize returns the text spoken as Ogg Opus audio; language is an optional ISO-639-1 hint
+	Synthesize(ctx context.Context, text, language string) ([]byte, error)
+}
+
+// New creates the configured synthesizer, or returns nil when speech synthesis is disabled
+func New(cfg *config.Config) (Synthesizer, error) {
+	timeout := time.Duration(cfg.TTSTimeout) * time.Second
+	switch cfg.TTSProvider {
+	case "":
+		return nil, nil
+	case ProviderOpenAI:
+		return NewOpenAI(cfg.TTSBaseURL, cfg.TTSAPIKey, cfg.TTSModel, cfg.TTSVoice, timeout), nil
+	case ProviderCommand:
+		return NewCommand(cfg.TTSCommand, timeout)
+	default:
+		return nil, fmt.Errorf("unknown speech synthesis provider %q", cfg.TTSProvider)
+	}
+}
@@ -2,20 +2,51 @@ package whatsapp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/config"
 	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
 )
 
+// Guard decides whether a message may be sent to a phone number
+type Guard interface {
+	// Allowed returns an error if the number must not be messaged
+	Allowed(to string) error
+}
+
+// APIError is an error response from the WhatsApp API
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("WhatsApp API error: %s, status code: %d", e.Body, e.StatusCode)
+}
+
+// IsRetryable reports whether a failed send may succeed later: rate limits, server errors and network failures
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
 // Client handles communication with the WhatsApp API
 type Client struct {
 	config *config.Config
 	client *http.Client
+	guard  Guard
 }
 
 // NewClient creates a new WhatsApp client
@@ -26,36 +57,128 @@ func NewClient(cfg *config.Config) *Client {
 	}
 }
 
+// SetGuard installs a guard that every outgoing message must pass
+func (c *Client) SetGuard(guard Guard) {
+	c.guard = guard
+}
+
 // SendMessage sends a message to a WhatsApp user
 func (c *Client) SendMessage(to string, text string) error {
-	// Construct the URL
-	url := fmt.Sprintf("%s/%s/messages", c.config.WhatsAppAPIURL, c.config.WhatsAppPhoneID)
+	_, err := c.Send(TextMessage(to, text))
+	return err
+}
+
+// SendButtons sends a message with up to three reply buttons
+func (c *Client) SendButtons(to string, text string, buttons []models.WhatsAppButtonReply) error {
+	_, err := c.Send(ButtonsMessage(to, text, buttons))
+	return err
+}
 
-	// Create the request body
-	reqBody := models.WhatsAppSendMessageRequest{
-		MessagingProduct: "whatsapp",
-		RecipientType:    "individual",
-		To:               to,
-		Type:             "text",
-		Text: struct {
-			PreviewURL bool   `json:"preview_url"`
-			Body       string `json:"body"`
-		}{
+// SendTemplate sends a pre-approved template message, filling its body parameters in order
+func (c *Client) SendTemplate(to, name, language string, parameters []string) error {
+	_, err := c.Send(TemplateMessage(to, name, language, parameters))
+	return err
+}
+
+// TextMessage builds a text message
+func TextMessage(to string, text string) *models.WhatsAppSendMessageRequest {
+	return &models.WhatsAppSendMessageRequest{
+		To:   to,
+		Type: "text",
+		Text: &models.WhatsAppText{
 			PreviewURL: false,
 			Body:       text,
 		},
 	}
+}
+
+// ButtonsMessage builds an interactive message with reply buttons
+func ButtonsMessage(to string, text string, buttons []models.WhatsAppButtonReply) *models.WhatsAppSendMessageRequest {
+	interactive := &models.WhatsAppInteractive{Type: "button"}
+	interactive.Body.Text = text
+	for _, button := range buttons {
+		interactive.Action.Buttons = append(interactive.Action.Buttons, models.WhatsAppButton{
+			Type:  "reply",
+			Reply: button,
+		})
+	}
+
+	return &models.WhatsAppSendMessageRequest{
+		To:          to,
+		Type:        "interactive",
+		Interactive: interactive,
+	}
+}
+
+// TemplateMessage builds a template message with the given body parameters
+func TemplateMessage(to, name, language string, parameters []string) *models.WhatsAppSendMessageRequest {
+	template := &models.WhatsAppTemplate{Name: name}
+	template.Language.Code = language
+	if len(parameters) > 0 {
+		body := models.WhatsAppTemplateComponent{Type: "body"}
+		for _, parameter := range parameters {
+			body.Parameters = append(body.Parameters, models.WhatsAppTemplateParameter{
+				Type: "text",
+				Text: parameter,
+			})
+		}
+		template.Components = append(template.Components, body)
+	}
+
+	return &models.WhatsAppSendMessageRequest{
+		To:       to,
+		Type:     "template",
+		Template: template,
+	}
+}
+
+// MediaMessage builds an image, document, audio or video message
+func MediaMessage(to, mediaType string, media *models.WhatsAppMedia) (*models.WhatsAppSendMessageRequest, error) {
+	reqBody := &models.WhatsAppSendMessageRequest{
+		To:   to,
+		Type: mediaType,
+	}
+	switch mediaType {
+	case "image":
+		reqBody.Image = media
+	case "document":
+		reqBody.Document = media
+	case "audio":
+		reqBody.Audio = media
+	case "video":
+		reqBody.Video = media
+	default:
+		return nil, fmt.Errorf("unsupported media type %q", mediaType)
+	}
+	return reqBody, nil
+}
+
+// Send posts a message to the WhatsApp API and returns its message ID (wamid)
+func (c *Client) Send(reqBody *models.WhatsAppSendMessageRequest) (string, error) {
+	// Refuse numbers the guard doesn't allow
+	if c.guard != nil {
+		if err := c.guard.Allowed(reqBody.To); err != nil {
+			return "", err
+		}
+	}
+
+	// Construct the URL
+	url := fmt.Sprintf("%s/%s/messages", c.config.WhatsAppAPIURL, c.config.WhatsAppPhoneID)
+
+	// Every message goes to an individual user
+	reqBody.MessagingProduct = "whatsapp"
+	reqBody.RecipientType = "individual"
 
 	// Convert the body to JSON
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+		return "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	// Create the request
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -65,17 +188,28 @@ func (c *Client) SendMessage(to string, text string) error {
 	// Send the request
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return "", fmt.Errorf("failed to send message: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check response status
+	body, err := io.ReadAll(resp.Body)
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("WhatsApp API error: %s, status code: %d", string(body), resp.StatusCode)
+		return "", &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return nil
+	// Extract the message ID
+	var sendResp models.WhatsAppSendMessageResponse
+	if err := json.Unmarshal(body, &sendResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(sendResp.Messages) == 0 {
+		return "", fmt.Errorf("WhatsApp API returned no message ID")
+	}
+	return sendResp.Messages[0].ID, nil
 }
 
 // VerifyWebhook verifies the WhatsApp webhook
@@ -93,18 +227,56 @@ func (c *Client) ProcessWebhook(webhookData *models.WhatsAppWebhookRequest) ([]m
 			for _, change := range entry.Changes {
 				if change.Field == "messages" {
 					for _, msg := range change.Value.Messages {
-						if msg.Type == "text" {
-							// Convert timestamp to time.Time
-							timestamp := convertTimestamp(msg.Timestamp)
-							
-							message := models.Message{
-								ID:        msg.ID,
-								From:      msg.From,
-								Text:      msg.Text.Body,
-								Timestamp: timestamp,
+						// Convert timestamp to time.Time
+						timestamp := convertTimestamp(msg.Timestamp)
+
+						message := models.Message{
+							ID:        msg.ID,
+							From:      msg.From,
+							Timestamp: timestamp,
+						}
+
+						// Extract the text, treating tapped buttons as the text of their label.
+						// Voice notes are left for transcription and the caption of an image or document is its text.
+						switch msg.Type {
+						case "text":
+							message.Text = msg.Text.Body
+						case "interactive":
+							reply := msg.Interactive.ButtonReply
+							if msg.Interactive.Type == "list_reply" {
+								reply = msg.Interactive.ListReply
 							}
-							messages = append(messages, message)
+							message.Text = reply.Title
+							message.ReplyID = reply.ID
+						case "button":
+							message.Text = msg.Button.Text
+							message.ReplyID = msg.Button.Payload
+						case "audio":
+							message.Media = &models.MessageMedia{
+								Type:     models.MediaAudio,
+								ID:       msg.Audio.ID,
+								MIMEType: msg.Audio.MimeType,
+								Voice:    msg.Audio.Voice,
+							}
+						case "image":
+							message.Text = msg.Image.Caption
+							message.Media = &models.MessageMedia{
+								Type:     models.MediaImage,
+								ID:       msg.Image.ID,
+								MIMEType: msg.Image.MimeType,
+							}
+						case "document":
+							message.Text = msg.Document.Caption
+							message.Media = &models.MessageMedia{
+								Type:     models.MediaDocument,
+								ID:       msg.Document.ID,
+								MIMEType: msg.Document.MimeType,
+								Filename: msg.Document.Filename,
+							}
+						default:
+							continue
 						}
+						messages = append(messages, message)
 					}
 				}
 			}
@@ -114,10 +286,42 @@ func (c *Client) ProcessWebhook(webhookData *models.WhatsAppWebhookRequest) ([]m
 	return messages, nil
 }
 
-// Helper function to convert timestamp from string to time.Time
-// This is a placeholder - you would need to implement proper conversion based on the format
+// ProcessStatuses extracts the delivery status updates from webhook data
+func (c *Client) ProcessStatuses(webhookData *models.WhatsAppWebhookRequest) []models.MessageStatus {
+	if webhookData.Object != "whatsapp_business_account" {
+		return nil
+	}
+
+	var statuses []models.MessageStatus
+	for _, entry := range webhookData.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			for _, st := range change.Value.Statuses {
+				status := models.MessageStatus{
+					MessageID:   st.ID,
+					RecipientID: st.RecipientID,
+					Status:      st.Status,
+					Timestamp:   convertTimestamp(st.Timestamp),
+				}
+				if len(st.Errors) > 0 {
+					status.Error = fmt.Sprintf("%d: %s", st.Errors[0].Code, st.Errors[0].Title)
+				}
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	return statuses
+}
+
+// Helper function to convert a webhook timestamp, in Unix seconds, to time.Time.
+// Missing or malformed timestamps fall back to the current time.
 func convertTimestamp(timestamp string) time.Time {
-	// For simplicity, just return current time. In a real implementation, 
-	// you'd parse the timestamp from WhatsApp.
-	return time.Now()
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Now()
+	}
+	return time.Unix(seconds, 0)
 } 
\ No newline at end of file
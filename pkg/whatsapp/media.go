@@ -0,0 +1,145 @@
+package whatsapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+
+	"github.com/gilanglahat22/whatsapp-chatbot/pkg/models"
+)
+
+// ErrMediaTooLarge is returned for media files over the configured size limit
+var ErrMediaTooLarge = errors.New("media file is too large")
+
+// Media is a file downloaded from WhatsApp
+type Media struct {
+	Data     []byte
+	MIMEType string
+}
+
+// DownloadMedia fetches a file a user sent, by its media ID
+func (c *Client) DownloadMedia(ctx context.Context, mediaID string) (*Media, error) {
+	maxSize := int64(c.config.WhatsAppMediaMaxMB) << 20
+
+	// Look up the temporary download URL
+	resp, err := c.get(ctx, fmt.Sprintf("%s/%s", c.config.WhatsAppAPIURL, mediaID))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var info models.WhatsAppMediaInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal media info: %w", err)
+	}
+	if info.URL == "" {
+		return nil, fmt.Errorf("WhatsApp API returned no URL for media %s", mediaID)
+	}
+	if info.FileSize > maxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	// Download the file itself, which needs the same token
+	file, err := c.get(ctx, info.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrMediaTooLarge
+	}
+
+	mimeType := info.MimeType
+	if mimeType == "" {
+		mimeType = file.Header.Get("Content-Type")
+	}
+	return &Media{Data: data, MIMEType: mimeType}, nil
+}
+
+// UploadMedia uploads a file that messages can then send by its media ID
+func (c *Client) UploadMedia(ctx context.Context, data []byte, mimeType, filename string) (string, error) {
+	// Build the multipart form
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("messaging_product", "whatsapp")
+	form.WriteField("type", mimeType)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", mimeType)
+	file, err := form.CreatePart(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to create form: %w", err)
+	}
+
+	// Create the request
+	url := fmt.Sprintf("%s/%s/media", c.config.WhatsAppAPIURL, c.config.WhatsAppPhoneID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.WhatsAppToken))
+
+	// Send the request
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 400 {
+		return "", &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if uploaded.ID == "" {
+		return "", errors.New("WhatsApp API returned no media ID")
+	}
+	return uploaded.ID, nil
+}
+
+// get makes an authenticated GET request to the WhatsApp API
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.WhatsAppToken))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
@@ -0,0 +1,14 @@
+package whatsapp
+
+import "strings"
+
+// NormalizePhone strips formatting so numbers compare equal, e.g. "+62 812-3456" becomes "628123456"
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}